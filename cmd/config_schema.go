@@ -49,7 +49,7 @@ var configSchemaCmd = &cobra.Command{
 			fmt.Println(string(data))
 			return nil
 		}
-		if err := os.WriteFile(configSchemaFlags.output, data, 0644); err != nil {
+		if err := os.WriteFile(configSchemaFlags.output, append(data, '\n'), 0644); err != nil {
 			return err
 		}
 		log.Info().
@@ -18,10 +18,12 @@
 package cmd
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime/debug"
 	"strconv"
@@ -29,21 +31,32 @@ import (
 	"text/template"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/jira"
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/RiskIdent/jelease/pkg/version"
+	"github.com/joho/godotenv"
 	"github.com/mitchellh/mapstructure"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	cfg config.Config
+	cfg     config.Config
+	cfgFile string
+	envFile string
 
-	appVersion string // may be set via `go build` flags
-	goVersion  string
+	// may be set via `go build` flags
+	appVersion string
+	gitCommit  string
+	buildDate  string
+
+	goVersion string
 )
 
 var rootCmd = &cobra.Command{
@@ -54,6 +67,7 @@ var rootCmd = &cobra.Command{
 		log.Debug().
 			Str("go", goVersion).
 			Str("version", appVersion).
+			Str("commit", gitCommit).
 			Msg("Jelease")
 		return configSetup()
 	},
@@ -62,21 +76,17 @@ var rootCmd = &cobra.Command{
 func Execute(defaultConfig config.Config) {
 	cfg = defaultConfig
 
-	// Add flag definitons here that need to be binded with configs
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", os.Getenv("CONFIG_FILE"), "Path to config file, instead of searching the default locations (env: CONFIG_FILE)")
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", os.Getenv("ENV_FILE"), "Path to .env file to load environment variables from (env: ENV_FILE) (default \""+defaultEnvFile+"\")")
+
 	// NOTE: These need to be added AFTER the "cfg = defaultConfig"
-	rootCmd.PersistentFlags().String("jira.url", cfg.Jira.URL, "Full URL, including protocol, of the Jira website")
-	rootCmd.PersistentFlags().Bool("jira.skipCertVerify", cfg.Jira.SkipCertVerify, "(INSECURE) Skip TLS/SSL certificate verification")
-	rootCmd.PersistentFlags().String("jira.auth.token", cfg.Jira.Auth.Token, "Jira personal access token (PAT)")
-	rootCmd.PersistentFlags().String("jira.issue.status", cfg.Jira.Issue.Status, "Jira issue status on created issues")
-	rootCmd.PersistentFlags().String("jira.issue.type", cfg.Jira.Issue.Type, "Jira issue type on created issues")
-	rootCmd.PersistentFlags().String("jira.issue.project", cfg.Jira.Issue.Project, `Jira project name to search for issues in (example: "OP")`)
-	rootCmd.PersistentFlags().Uint16("http.port", cfg.HTTP.Port, "Which HTTP port to run the server on.")
-	rootCmd.PersistentFlags().String("github.tempdir", util.Deref(cfg.GitHub.TempDir, os.TempDir()), "Which folder to clone repositories into")
-	rootCmd.PersistentFlags().Bool("dryrun", cfg.DryRun, "Do not alter any state, e.g skip creating Jira tickets or GitHub PRs")
-	rootCmd.PersistentFlags().Var(&cfg.Log.Level, "log.level", "Sets the logging level")
-	rootCmd.PersistentFlags().Var(&cfg.Log.Format, "log.format", "Sets the logging format")
+	addConfigFlags(rootCmd.PersistentFlags())
 	viper.BindPFlags(rootCmd.PersistentFlags())
 
+	// Environment variables override config files, but are overridden by flags.
+	// E.g JELEASE_JIRA_AUTH_TOKEN sets the jira.auth.token config field
+	envSetup(viper.GetViper())
+
 	loggerSetup() // set up logging first using default config
 
 	err := rootCmd.Execute()
@@ -92,12 +102,20 @@ func init() {
 			appVersion = buildInfo.Main.Version
 		}
 		goVersion = strings.TrimPrefix(buildInfo.GoVersion, "go")
-	} else {
+		for _, setting := range buildInfo.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && gitCommit == "":
+				gitCommit = setting.Value
+			case setting.Key == "vcs.time" && buildDate == "":
+				buildDate = setting.Value
+			}
+		}
 	}
 	if appVersion == "" {
 		appVersion = "unknown"
 	}
 	rootCmd.Version = appVersion
+	jira.AppVersion = appVersion
 
 	// config.FuncsMap must be set before the first time the config is parsed,
 	// which happens first in the main() function in main.go (in repo root)
@@ -122,6 +140,41 @@ func init() {
 			}
 			return toVer.Bump(addVer).String()
 		},
+		"semverMajor": func(value string) uint {
+			// "v1.2.3" | semverMajor => 1
+			ver, err := version.Parse(value)
+			if err != nil {
+				panic(fmt.Sprintf("semverMajor %q: %s", value, err))
+			}
+			return ver.Segment(0)
+		},
+		"semverMinor": func(value string) uint {
+			// "v1.2.3" | semverMinor => 2
+			ver, err := version.Parse(value)
+			if err != nil {
+				panic(fmt.Sprintf("semverMinor %q: %s", value, err))
+			}
+			return ver.Segment(1)
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": func(value string) string {
+			return cases.Title(language.Und, cases.NoLower).String(value)
+		},
+		"trimPrefix": func(prefix, value string) string {
+			return strings.TrimPrefix(value, prefix)
+		},
+		"trimSuffix": func(suffix, value string) string {
+			return strings.TrimSuffix(value, suffix)
+		},
+		"replace": func(old, new, value string) string {
+			return strings.ReplaceAll(value, old, new)
+		},
+		"jqlQuote": jira.QuoteJQL,
+		"displayProvider": func(provider string) string {
+			// "pypi" | displayProvider => "PyPI"
+			return cfg.Jira.Issue.DisplayProvider(provider)
+		},
 		"sanitizePath": func(path string) string {
 			path = strings.ToLower(path)
 			path = filepath.ToSlash(path)
@@ -201,20 +254,112 @@ func init() {
 	}
 }
 
+// addConfigFlags adds the flags for the commonly used config fields, as
+// listed in the README, using the current config values as defaults.
+// The other fields can only be set via the config files or env vars.
+func addConfigFlags(flags *pflag.FlagSet) {
+	flags.String("jira.url", cfg.Jira.URL, "Full URL, including protocol, of the Jira website")
+	flags.Bool("jira.skipCertVerify", cfg.Jira.SkipCertVerify, "(INSECURE) Skip TLS/SSL certificate verification")
+	flags.String("jira.caCertFile", cfg.Jira.CACertFile, "Path to PEM-encoded CA certificates to trust for Jira, in addition to the system certificates")
+	flags.Uint("jira.startupRetry.maxRetries", cfg.Jira.StartupRetry.MaxRetries, "Number of times to retry reaching Jira on startup")
+	flags.Var(&cfg.Jira.StartupRetry.Delay, "jira.startupRetry.delay", "Delay between retries when reaching Jira on startup")
+	flags.Var(&cfg.Jira.Auth.Type, "jira.auth.type", "Jira auth type, one of: pat, token")
+	flags.String("jira.auth.user", cfg.Jira.Auth.User, "Jira username, used with auth type token")
+	flags.String("jira.auth.token", cfg.Jira.Auth.Token, "Jira personal access token (PAT)")
+	flags.String("jira.issue.status", cfg.Jira.Issue.Status, "Jira issue status on created issues")
+	flags.String("jira.issue.postCreateTransition", cfg.Jira.Issue.PostCreateTransition, "Jira issue status to transition created issues to")
+	flags.StringSlice("jira.issue.searchStatuses", cfg.Jira.Issue.SearchStatuses, "Additional Jira issue statuses to search for existing issues in")
+	flags.String("jira.issue.type", cfg.Jira.Issue.Type, "Jira issue type on created issues")
+	flags.String("jira.issue.project", cfg.Jira.Issue.Project, `Jira project name to search for issues in (example: "OP")`)
+	flags.String("jira.issue.priority", cfg.Jira.Issue.Priority, "Jira issue priority on created issues")
+	flags.String("jira.issue.assignee", cfg.Jira.Issue.Assignee, "Jira user to assign created issues to")
+	flags.String("jira.issue.reporter", cfg.Jira.Issue.Reporter, "Jira user to set as reporter on created issues")
+	flags.StringSlice("jira.issue.watchers", cfg.Jira.Issue.Watchers, "Jira users to add as watchers on created issues")
+	flags.String("jira.issue.securityLevel", cfg.Jira.Issue.SecurityLevel, "Jira issue security level to set on created issues")
+	flags.StringSlice("jira.issue.labels", cfg.Jira.Issue.Labels, "Jira labels on created issues")
+	flags.Uint16("http.port", cfg.HTTP.Port, "Which HTTP port to run the server on.")
+	flags.String("http.webhookPath", cfg.HTTP.WebhookPath, "URL path to serve the newreleases.io webhook receiver on")
+	flags.String("github.tempdir", util.Deref(cfg.GitHub.TempDir, os.TempDir()), "Which folder to clone repositories into")
+	flags.Bool("dryrun", cfg.DryRun, "Do not alter any state, e.g skip creating Jira tickets or GitHub PRs")
+	flags.Var(&cfg.Log.Level, "log.level", "Sets the logging level")
+	flags.Var(&cfg.Log.Format, "log.format", "Sets the logging format")
+}
+
+// envSetup makes the JELEASE_ environment variables override the config
+// files. E.g JELEASE_JIRA_AUTH_TOKEN sets the jira.auth.token config field.
+func envSetup(v *viper.Viper) {
+	v.SetEnvPrefix("jelease")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	bindEnvs(v, reflect.TypeOf(config.Config{}), "")
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// bindEnvs registers all config fields with viper. Needed as viper's
+// AutomaticEnv only looks up keys that viper already knows about, and the
+// default values are not read via viper, so environment variables would
+// otherwise be ignored for fields missing from the config file.
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+			bindEnvs(v, fieldType, key+".")
+			continue
+		}
+		v.BindEnv(key)
+	}
+}
+
+const defaultEnvFile = ".env"
+
+// loadEnvFile sets the environment variables from the .env file, e.g
+// JELEASE_JIRA_AUTH_TOKEN, without overriding variables that are already
+// set. A missing file is only logged as a warning, as the file is optional.
+func loadEnvFile(path string) {
+	if path == "" {
+		path = defaultEnvFile
+	}
+	if err := godotenv.Load(path); err != nil {
+		log.Warn().Err(err).Str("file", path).Msg("Failed to load .env file. Skipping it.")
+		return
+	}
+	log.Debug().Str("file", path).Msg("Loaded .env file.")
+}
+
 func configSetup() error {
-	viper.SetConfigName("jelease")
+	loadEnvFile(envFile)
+
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("/etc/jelease/")
-	if homePath, err := os.UserHomeDir(); err == nil {
-		viper.AddConfigPath(filepath.Join(homePath, ".jelease.yaml"))
-	}
-	if cfgPath, err := os.UserConfigDir(); err == nil {
-		viper.AddConfigPath(cfgPath)
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.SetConfigName("jelease")
+		viper.AddConfigPath("/etc/jelease/")
+		if homePath, err := os.UserHomeDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(homePath, ".jelease.yaml"))
+		}
+		if cfgPath, err := os.UserConfigDir(); err == nil {
+			viper.AddConfigPath(cfgPath)
+		}
+		viper.AddConfigPath(".")
 	}
-	viper.AddConfigPath(".")
 	if err := viper.ReadInConfig(); err != nil {
 		return err
 	}
+	log.Debug().Str("file", viper.ConfigFileUsed()).Msg("Read config file.")
 
 	if err := viper.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
 		mapstructure.TextUnmarshallerHookFunc(),
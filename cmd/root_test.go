@@ -19,10 +19,15 @@ package cmd
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 	"text/template"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
 )
 
 func TestTemplate(t *testing.T) {
@@ -35,6 +40,57 @@ func TestTemplate(t *testing.T) {
 	assertTemplateData(t, `{{fromJson . }}`, `map[Name:Bangladesh]`, `{"Name": "Bangladesh"}`)
 	assertTemplateData(t, `{{fromYaml . }}`, `map[Name:Bangladesh]`, `{"Name": "Bangladesh"}`)
 	assertTemplateData(t, `{{toYaml . }}`, "Name: Bangladesh\n", map[string]string{"Name": "Bangladesh"})
+	assertTemplate(t, `{{lower "Jelease"}}`, "jelease")
+	assertTemplate(t, `{{upper "Jelease"}}`, "JELEASE")
+	assertTemplate(t, `{{title "jelease release notes"}}`, "Jelease Release Notes")
+	assertTemplate(t, `{{"v1.2.3" | trimPrefix "v"}}`, "1.2.3")
+	assertTemplate(t, `{{"neuvector-chart" | trimSuffix "-chart"}}`, "neuvector")
+	assertTemplate(t, `{{"@scope/pkg" | replace "/" "-"}}`, "@scope-pkg")
+	assertTemplate(t, `{{semverMajor "v1.2.3"}}`, "1")
+	assertTemplate(t, `{{semverMinor "v1.2.3"}}`, "2")
+	assertTemplate(t, `{{semverMinor "5"}}`, "0")
+}
+
+func TestTemplateRender(t *testing.T) {
+	var tmpl config.Template
+	if err := tmpl.Set(`Update {{ .Project | title }} to v{{ semverMajor .Version }}.{{ semverMinor .Version }}`); err != nil {
+		t.Fatalf("parse template: %s", err)
+	}
+	got, err := tmpl.Render(map[string]string{"Project": "neuvector", "Version": "v5.1.3"})
+	if err != nil {
+		t.Fatalf("render template: %s", err)
+	}
+	want := "Update Neuvector to v5.1"
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestTemplateRender_displayProvider(t *testing.T) {
+	defer func(orig map[string]string) { cfg.Jira.Issue.ProviderDisplayNames = orig }(cfg.Jira.Issue.ProviderDisplayNames)
+	cfg.Jira.Issue.ProviderDisplayNames = map[string]string{"pypi": "PyPI", "npm": "npm (Node.js)"}
+
+	var tmpl config.Template
+	if err := tmpl.Set(`Update {{ .Project }} from {{ .Provider | displayProvider }}`); err != nil {
+		t.Fatalf("parse template: %s", err)
+	}
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{provider: "pypi", want: "Update requests from PyPI"},
+		{provider: "npm", want: "Update requests from npm (Node.js)"},
+		{provider: "github", want: "Update requests from github"},
+	}
+	for _, tc := range tests {
+		got, err := tmpl.Render(map[string]string{"Project": "requests", "Provider": tc.provider})
+		if err != nil {
+			t.Fatalf("render template: %s", err)
+		}
+		if got != tc.want {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+	}
 }
 
 func assertTemplate(t *testing.T, templateString string, want string) {
@@ -72,3 +128,93 @@ func assertTemplateData(t *testing.T, templateString string, want string, data a
 		t.Errorf("Template %q: want %q got %q", templateString, want, got)
 	}
 }
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.staging")
+	content := "JELEASE_TEST_ENVFILE_NEW=from-file\nJELEASE_TEST_ENVFILE_SET=from-file\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JELEASE_TEST_ENVFILE_SET", "from-env")
+	t.Cleanup(func() { os.Unsetenv("JELEASE_TEST_ENVFILE_NEW") })
+
+	loadEnvFile(path)
+
+	if got := os.Getenv("JELEASE_TEST_ENVFILE_NEW"); got != "from-file" {
+		t.Errorf("want variable from file to be set, got %q", got)
+	}
+	if got := os.Getenv("JELEASE_TEST_ENVFILE_SET"); got != "from-env" {
+		t.Errorf("want existing variable to not be overridden, got %q", got)
+	}
+}
+
+func TestEnvSetup_keyMissingFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "min.yaml")
+	if err := os.WriteFile(path, []byte("jira:\n  url: https://jira.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JELEASE_HTTP_WEBHOOKSECRET", "fromenv")
+	t.Setenv("JELEASE_JIRA_ISSUE_LABELS", "jelease,renovate")
+
+	v := viper.New()
+	envSetup(v)
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	got := config.Config{HTTP: config.HTTP{Port: 8080}}
+	if err := v.Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.HTTP.WebhookSecret != "fromenv" {
+		t.Errorf("want webhook secret from env, got %q", got.HTTP.WebhookSecret)
+	}
+	if want := []string{"jelease", "renovate"}; !slices.Equal(got.Jira.Issue.Labels, want) {
+		t.Errorf("want labels %v, got %v", want, got.Jira.Issue.Labels)
+	}
+	if got.Jira.URL != "https://jira.example.com" {
+		t.Errorf("want URL from file, got %q", got.Jira.URL)
+	}
+	if got.HTTP.Port != 8080 {
+		t.Errorf("want unset env vars to keep default, got port %d", got.HTTP.Port)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "JELEASE_JIRA_URL=https://dotenv.example.com\n" +
+		"JELEASE_JIRA_ISSUE_PROJECT=DOTENV\n" +
+		"JELEASE_JIRA_ISSUE_TYPE=Dotenv\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JELEASE_JIRA_URL", "https://env.example.com")
+	t.Setenv("JELEASE_JIRA_ISSUE_PROJECT", "ENV")
+	os.Unsetenv("JELEASE_JIRA_ISSUE_TYPE")
+	t.Cleanup(func() { os.Unsetenv("JELEASE_JIRA_ISSUE_TYPE") })
+
+	flags := pflag.NewFlagSet("jelease", pflag.ContinueOnError)
+	addConfigFlags(flags)
+	if err := flags.Parse([]string{"--jira.url", "https://flag.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	loadEnvFile(path)
+	v := viper.New()
+	v.BindPFlags(flags)
+	envSetup(v)
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "jira.url", want: "https://flag.example.com"},
+		{key: "jira.issue.project", want: "ENV"},
+		{key: "jira.issue.type", want: "Dotenv"},
+	}
+	for _, tc := range tests {
+		if got := v.GetString(tc.key); got != tc.want {
+			t.Errorf("want %s %q, got %q", tc.key, tc.want, got)
+		}
+	}
+}
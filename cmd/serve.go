@@ -18,15 +18,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/jira"
 	"github.com/RiskIdent/jelease/pkg/server"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 )
 
 var serveCmd = &cobra.Command{
@@ -34,7 +40,7 @@ var serveCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		err := run()
 		if errors.Is(err, http.ErrServerClosed) {
-			log.Error().Msg("Server closed.")
+			log.Info().Msg("Server closed.")
 		} else if err != nil {
 			log.Error().Err(err).Msg("Error starting server.")
 			os.Exit(1)
@@ -47,21 +53,280 @@ func init() {
 }
 
 func run() error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	log.Info().
+		Str("jiraURL", cfg.Jira.URL).
+		Stringer("jiraAuthType", cfg.Jira.Auth.Type).
+		Bool("jiraTokenSet", cfg.Jira.Auth.Token != "").
+		Strs("projects", cfg.Jira.Issue.AllProjects()).
+		Str("status", cfg.Jira.Issue.Status).
+		Str("issueType", cfg.Jira.Issue.Type).
+		Uint16("port", cfg.HTTP.Port).
+		Str("webhookPath", cfg.HTTP.WebhookPath).
+		Bool("webhookSecretSet", cfg.HTTP.WebhookSecret != "").
+		Bool("dryRun", cfg.DryRun).
+		Msg("Validated configuration.")
+
 	jiraClient, err := jira.New(&cfg.Jira)
 	if err != nil {
 		return fmt.Errorf("create jira client: %w", err)
 	}
 
-	if err := jiraClient.ProjectMustExist(cfg.Jira.Issue.Project); err != nil {
-		return fmt.Errorf("check if configured project exists: %w", err)
+	if err := waitForJira(jiraClient, cfg.Jira.StartupRetry.MaxRetries, cfg.Jira.StartupRetry.Delay.Duration()); err != nil {
+		return fmt.Errorf("check Jira connection: %w", err)
+	}
+	log.Debug().Str("url", cfg.Jira.URL).Msg("Jira reachable ✓")
+
+	if err := checkJiraConfig(jiraClient, log.Logger); err != nil {
+		return err
+	}
+
+	mappedConnections := map[string]bool{}
+	for _, name := range cfg.Jira.ConnectionMapping {
+		mappedConnections[name] = true
+	}
+	namedJiraClients := make(map[string]jira.Client, len(cfg.Jira.Connections))
+	for name, conn := range cfg.Jira.Connections {
+		connCfg := cfg.Jira.WithConnection(conn)
+		namedClient, err := jira.New(&connCfg)
+		if err != nil {
+			return fmt.Errorf("create jira client for connection %q: %w", name, err)
+		}
+		if err := waitForJira(namedClient, cfg.Jira.StartupRetry.MaxRetries, cfg.Jira.StartupRetry.Delay.Duration()); err != nil {
+			return fmt.Errorf("check Jira connection %q: %w", name, err)
+		}
+		log.Debug().Str("connection", name).Str("url", conn.URL).Msg("Configured Jira connection reachable ✓")
+		if mappedConnections[name] {
+			// The issue config is shared by all connections
+			if err := checkJiraConfig(namedClient, log.With().Str("connection", name).Logger()); err != nil {
+				return fmt.Errorf("check Jira connection %q: %w", name, err)
+			}
+		}
+		namedJiraClients[name] = namedClient
+	}
+
+	buildInfo := server.BuildInfo{
+		Version:   appVersion,
+		Commit:    gitCommit,
+		BuildDate: buildDate,
+		GoVersion: goVersion,
+	}
+	log.Info().
+		Str("version", buildInfo.Version).
+		Str("commit", buildInfo.Commit).
+		Str("buildDate", buildInfo.BuildDate).
+		Msg("Starting jelease.")
+
+	s := server.New(&cfg, buildInfo, jiraClient, namedJiraClients)
+	return s.Serve()
+}
+
+// checkJiraConfig checks that the configured projects, statuses, users, etc
+// exist in the Jira instance, so misconfigurations are found on startup
+// instead of when receiving the first release.
+func checkJiraConfig(jiraClient jira.Client, logger zerolog.Logger) error {
+	for _, project := range cfg.Jira.Issue.AllProjects() {
+		if err := jiraClient.ProjectMustExist(project); err != nil {
+			return fmt.Errorf("check if configured project exists: %w", err)
+		}
+		logger.Debug().Str("project", project).Msg("Configured project found ✓")
+
+		if err := jiraClient.PermissionsMustExist(project, []string{"CREATE_ISSUES", "EDIT_ISSUES"}); err != nil {
+			return fmt.Errorf("check permissions in configured project: %w", err)
+		}
+		logger.Debug().Str("project", project).Msg("Required permissions in project found ✓")
+
+		if level := cfg.Jira.Issue.SecurityLevel; level != "" {
+			if err := jiraClient.SecurityLevelMustExist(project, level); err != nil {
+				return fmt.Errorf("check if configured security level exists: %w", err)
+			}
+			logger.Debug().Str("project", project).Str("securityLevel", level).Msg("Configured security level found ✓")
+		}
+
+		if components := cfg.Jira.Issue.ComponentsForProject(project); len(components) > 0 {
+			if err := jiraClient.ComponentsMustExist(project, components); err != nil {
+				return fmt.Errorf("check if configured components exist: %w", err)
+			}
+			logger.Debug().Str("project", project).Strs("components", components).Msg("Configured components found ✓")
+		}
+	}
+
+	if security := cfg.Jira.Issue.Security; security.Project != "" {
+		if err := jiraClient.ProjectMustExist(security.Project); err != nil {
+			return fmt.Errorf("check if configured security project exists: %w", err)
+		}
+		if err := jiraClient.PermissionsMustExist(security.Project, []string{"CREATE_ISSUES", "LINK_ISSUES"}); err != nil {
+			return fmt.Errorf("check permissions in configured security project: %w", err)
+		}
+		if err := jiraClient.LinkTypeMustExist(security.LinkType); err != nil {
+			return fmt.Errorf("check if configured security issue link type exists: %w", err)
+		}
+		// The security issues are created with the same config as the
+		// regular issues, but are never searched for, so their status
+		// does not matter.
+		for _, issueType := range cfg.Jira.Issue.SecurityProjectTypes() {
+			if err := jiraClient.IssueTypeMustExist(security.Project, issueType); err != nil {
+				return fmt.Errorf("check if configured issue type exists in security project: %w", err)
+			}
+		}
+		if level := cfg.Jira.Issue.SecurityLevel; level != "" {
+			if err := jiraClient.SecurityLevelMustExist(security.Project, level); err != nil {
+				return fmt.Errorf("check if configured security level exists in security project: %w", err)
+			}
+		}
+		if components := cfg.Jira.Issue.ComponentsForProject(security.Project); len(components) > 0 {
+			if err := jiraClient.ComponentsMustExist(security.Project, components); err != nil {
+				return fmt.Errorf("check if configured components exist in security project: %w", err)
+			}
+		}
+		logger.Debug().
+			Str("project", security.Project).
+			Str("linkType", security.LinkType).
+			Msg("Configured security project, issue link type, issue types, and components found ✓")
 	}
-	log.Debug().Str("project", cfg.Jira.Issue.Project).Msg("Configured project found ✓")
 
 	if err := jiraClient.StatusMustExist(cfg.Jira.Issue.Status); err != nil {
 		return fmt.Errorf("check if configured default status exists: %w", err)
 	}
-	log.Debug().Str("status", cfg.Jira.Issue.Status).Msg("Configured default status found ✓")
+	logger.Debug().Str("status", cfg.Jira.Issue.Status).Msg("Configured default status found ✓")
 
-	s := server.New(&cfg, jiraClient)
-	return s.Serve()
+	if status := cfg.Jira.Issue.PostCreateTransition; status != "" {
+		if err := jiraClient.StatusMustExist(status); err != nil {
+			return fmt.Errorf("check if configured post-create transition status exists: %w", err)
+		}
+		logger.Debug().Str("status", status).Msg("Configured post-create transition status found ✓")
+	}
+
+	for _, status := range cfg.Jira.Issue.SearchStatuses {
+		if err := jiraClient.StatusMustExist(status); err != nil {
+			return fmt.Errorf("check if configured search status exists: %w", err)
+		}
+		logger.Debug().Str("status", status).Msg("Configured search status found ✓")
+	}
+
+	typesByProject := cfg.Jira.Issue.TypesByProject()
+	for _, project := range cfg.Jira.Issue.AllProjects() {
+		for _, issueType := range typesByProject[project] {
+			if err := jiraClient.WorkflowStatusMustExist(project, issueType, cfg.Jira.Issue.Status); err != nil {
+				return fmt.Errorf("check if configured issue type and default status exist in project workflow: %w", err)
+			}
+			logger.Debug().
+				Str("project", project).
+				Str("issueType", issueType).
+				Str("status", cfg.Jira.Issue.Status).
+				Msg("Configured issue type and default status found in project workflow ✓")
+			if err := createdIssueStatusMustBeSearched(jiraClient, logger, project, issueType, &cfg.Jira.Issue); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Jira.Issue.ParentKey != "" {
+		if err := jiraClient.IssueMustExist(cfg.Jira.Issue.ParentKey); err != nil {
+			return fmt.Errorf("check if configured parent issue exists: %w", err)
+		}
+		logger.Debug().Str("parent", cfg.Jira.Issue.ParentKey).Msg("Configured parent issue found ✓")
+	}
+
+	if cfg.Jira.Issue.CloseDuplicates {
+		if err := jiraClient.StatusMustExist(cfg.Jira.Issue.DuplicateStatus); err != nil {
+			return fmt.Errorf("check if configured duplicate status exists: %w", err)
+		}
+		logger.Debug().Str("status", cfg.Jira.Issue.DuplicateStatus).Msg("Configured duplicate status found ✓")
+	}
+
+	for _, priority := range []string{cfg.Jira.Issue.Priority, cfg.Jira.Issue.SecurityPriority} {
+		if priority == "" {
+			continue
+		}
+		if err := jiraClient.PriorityMustExist(priority); err != nil {
+			return fmt.Errorf("check if configured priority exists: %w", err)
+		}
+		logger.Debug().Str("priority", priority).Msg("Configured priority found ✓")
+	}
+
+	if cfg.Jira.Issue.Assignee != "" {
+		if err := jiraClient.UserMustExist(cfg.Jira.Issue.Assignee); err != nil {
+			return fmt.Errorf("check if configured assignee exists: %w", err)
+		}
+		logger.Debug().Str("assignee", cfg.Jira.Issue.Assignee).Msg("Configured assignee found ✓")
+	}
+
+	if cfg.Jira.Issue.Reporter != "" {
+		if err := jiraClient.UserMustExist(cfg.Jira.Issue.Reporter); err != nil {
+			return fmt.Errorf("check if configured reporter exists: %w", err)
+		}
+		logger.Debug().Str("reporter", cfg.Jira.Issue.Reporter).Msg("Configured reporter found ✓")
+	}
+
+	for _, watcher := range cfg.Jira.Issue.Watchers {
+		if err := jiraClient.UserMustExist(watcher); err != nil {
+			return fmt.Errorf("check if configured watcher exists: %w", err)
+		}
+		logger.Debug().Str("watcher", watcher).Msg("Configured watcher found ✓")
+	}
+	return nil
+}
+
+// waitForJira pings Jira until it responds, retrying up to maxRetries times
+// with the given delay in between, so jelease can be started before Jira.
+func waitForJira(client jira.Client, maxRetries uint, delay time.Duration) error {
+	for attempt := uint(0); ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := client.Ping(ctx)
+		cancel()
+		if err == nil || attempt >= maxRetries {
+			return err
+		}
+		log.Warn().
+			Err(err).
+			Uint("attempt", attempt+1).
+			Uint("maxRetries", maxRetries).
+			Dur("delay", delay).
+			Msg("Jira not reachable, retrying.")
+		time.Sleep(delay)
+	}
+}
+
+// createdIssueStatusMustBeSearched checks that created issues end up in one
+// of the searched statuses, as jelease never sets the status when creating
+// issues. Otherwise the created issues are never found when searching, and a
+// duplicate is created on every release. Created issues start in the initial
+// status of the workflow, or in the target status of the postCreateTransition
+// when one is configured.
+//
+// The workflow can only be looked up on Jira Cloud, with the
+// "Administer Jira" permission, so failing to look it up is only logged.
+func createdIssueStatusMustBeSearched(jiraClient jira.Client, logger zerolog.Logger, project, issueType string, cfg *config.JiraIssue) error {
+	statuses, err := jiraClient.WorkflowCreatedIssueStatuses(project, issueType)
+	if err != nil {
+		logger.Warn().Err(err).
+			Str("project", project).
+			Str("issueType", issueType).
+			Msg("Failed to look up initial status of project workflow. Skipping check.")
+		return nil
+	}
+	created := statuses.Initial
+	if cfg.PostCreateTransition != "" {
+		transitioned, ok := statuses.Transitioned(cfg.PostCreateTransition)
+		if !ok {
+			return fmt.Errorf("check if configured post-create transition exists in project workflow: no transition to or named %q found from initial status %q for issues of type %q in project %q",
+				cfg.PostCreateTransition, statuses.Initial, issueType, project)
+		}
+		created = transitioned
+	}
+	searched := cfg.StatusesToSearch()
+	if !slices.ContainsFunc(searched, func(status string) bool { return strings.EqualFold(status, created) }) {
+		return fmt.Errorf("check if created issues are in searched statuses: created issues of type %q in project %q end up in status %q, which is not one of the searched statuses %q, and would not be found when searching, so add %q to jira.issue.searchStatuses",
+			issueType, project, created, searched, created)
+	}
+	logger.Debug().
+		Str("project", project).
+		Str("issueType", issueType).
+		Str("initialStatus", statuses.Initial).
+		Str("createdStatus", created).
+		Msg("Created issues are found in searched statuses ✓")
+	return nil
 }
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/rs/zerolog"
+)
+
+type pingJira struct {
+	jira.Client
+	failures int
+	pings    int
+}
+
+func (j *pingJira) Ping(context.Context) error {
+	j.pings++
+	if j.pings <= j.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitForJira(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		maxRetries uint
+		wantErr    bool
+		wantPings  int
+	}{
+		{name: "reachable", failures: 0, maxRetries: 0, wantPings: 1},
+		{name: "no retries", failures: 1, maxRetries: 0, wantErr: true, wantPings: 1},
+		{name: "reachable after retries", failures: 2, maxRetries: 3, wantPings: 3},
+		{name: "retries exhausted", failures: 5, maxRetries: 2, wantErr: true, wantPings: 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &pingJira{failures: tc.failures}
+			err := waitForJira(client, tc.maxRetries, 0)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+			if client.pings != tc.wantPings {
+				t.Errorf("want %d pings, got %d", tc.wantPings, client.pings)
+			}
+		})
+	}
+}
+
+type workflowJira struct {
+	jira.Client
+	statuses jira.CreatedIssueStatuses
+}
+
+func (j workflowJira) WorkflowCreatedIssueStatuses(string, string) (jira.CreatedIssueStatuses, error) {
+	return j.statuses, nil
+}
+
+func TestCreatedIssueStatusMustBeSearched(t *testing.T) {
+	statuses := jira.CreatedIssueStatuses{
+		Initial:     "Backlog",
+		Transitions: map[string]string{"Select": "To Do"},
+	}
+	tests := []struct {
+		name    string
+		cfg     config.JiraIssue
+		wantErr bool
+	}{
+		{name: "initial is default status", cfg: config.JiraIssue{Status: "backlog"}},
+		{name: "initial not searched", cfg: config.JiraIssue{Status: "To Do"}, wantErr: true},
+		{name: "initial in searchStatuses", cfg: config.JiraIssue{Status: "To Do", SearchStatuses: []string{"Backlog"}}},
+		{name: "transitioned to default status", cfg: config.JiraIssue{Status: "To Do", PostCreateTransition: "Select"}},
+		{name: "transitioned status not searched", cfg: config.JiraIssue{Status: "Backlog", PostCreateTransition: "Select"}, wantErr: true},
+		{name: "transitioned status in postCreateTransition", cfg: config.JiraIssue{Status: "Backlog", PostCreateTransition: "to do"}},
+		{name: "transition not found", cfg: config.JiraIssue{Status: "To Do", PostCreateTransition: "Done"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := createdIssueStatusMustBeSearched(workflowJira{statuses: statuses}, zerolog.Nop(), "OP", "Task", &tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
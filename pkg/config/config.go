@@ -22,15 +22,19 @@ import (
 
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/invopop/jsonschema"
+	"golang.org/x/exp/slices"
 )
 
 type Config struct {
-	DryRun   bool `yaml:"dryRun"`
-	Packages []Package
-	GitHub   GitHub
-	Jira     Jira
-	HTTP     HTTP
-	Log      Log
+	DryRun       bool              `yaml:"dryRun"`
+	AliasMapping map[string]string `yaml:"aliasMapping"`
+	Filter       Filter
+	Packages     []Package
+	GitHub       GitHub
+	Jira         Jira
+	HTTP         HTTP
+	Slack        Slack
+	Log          Log
 }
 
 func (c Config) TryFindPackage(pkgName string) (Package, bool) {
@@ -42,6 +46,31 @@ func (c Config) TryFindPackage(pkgName string) (Package, bool) {
 	return Package{}, false
 }
 
+// Filter decides which newreleases.io projects to process.
+type Filter struct {
+	Allowlist       []string
+	Denylist        []string
+	SkipPrereleases bool `yaml:"skipPrereleases"`
+}
+
+// Allows returns true if the project should be processed. The allowlist
+// takes precedence, meaning the denylist is ignored if the allowlist is set.
+func (f Filter) Allows(project string) bool {
+	if len(f.Allowlist) > 0 {
+		return slices.Contains(f.Allowlist, project)
+	}
+	return !slices.Contains(f.Denylist, project)
+}
+
+// CanonicalProject returns the canonical name of a newreleases.io project,
+// for projects that have been renamed, or the name as-is if it has no alias.
+func (c Config) CanonicalProject(project string) string {
+	if canonical, ok := c.AliasMapping[project]; ok {
+		return canonical
+	}
+	return project
+}
+
 type Package struct {
 	Name  string
 	Repos []PackageRepo
@@ -93,10 +122,87 @@ type GitHubCommitter struct {
 }
 
 type Jira struct {
-	URL            string `jsonschema_extras:"format=uri"`
-	SkipCertVerify bool   `yaml:"skipCertVerify"`
+	URL                string         `jsonschema_extras:"format=uri"`
+	SkipCertVerify     bool           `yaml:"skipCertVerify"`
+	CACertFile         string         `yaml:"caCertFile"`
+	UserAgent          string         `yaml:"userAgent"`
+	APIVersion         JiraAPIVersion `yaml:"apiVersion"`
+	Timeout            Duration
+	OperationTimeouts  JiraOperationTimeouts `yaml:"operationTimeouts"`
+	Auth               JiraAuth
+	Retry              JiraRetry
+	StartupRetry       JiraStartupRetry `yaml:"startupRetry"`
+	Transport          JiraTransport
+	MaxSearchResults   uint `yaml:"maxSearchResults"`
+	MaxErrorBodyLength uint `yaml:"maxErrorBodyLength"`
+	Issue              JiraIssue
+
+	Connections       map[string]JiraConnection
+	ConnectionMapping map[string]string `yaml:"connectionMapping"`
+}
+
+// JiraConnection is an additional Jira instance to connect to, which
+// overrides the connection settings of the default Jira instance.
+type JiraConnection struct {
+	URL            string         `jsonschema_extras:"format=uri"`
+	SkipCertVerify bool           `yaml:"skipCertVerify"`
+	CACertFile     string         `yaml:"caCertFile"`
+	APIVersion     JiraAPIVersion `yaml:"apiVersion"`
 	Auth           JiraAuth
-	Issue          JiraIssue
+}
+
+// ConnectionName returns the name of the Jira connection to use for a given
+// newreleases.io project and provider, where a mapping of the project takes
+// precedence over a mapping of the provider. Returns an empty string if the
+// default Jira connection should be used.
+func (j Jira) ConnectionName(provider, project string) string {
+	if name, ok := j.ConnectionMapping[project]; ok {
+		return name
+	}
+	return j.ConnectionMapping[provider]
+}
+
+// WithConnection returns a copy of the Jira config, where the connection
+// settings are replaced with the given connection.
+func (j Jira) WithConnection(conn JiraConnection) Jira {
+	j.URL = conn.URL
+	j.SkipCertVerify = conn.SkipCertVerify
+	j.CACertFile = conn.CACertFile
+	if conn.APIVersion != "" {
+		j.APIVersion = conn.APIVersion
+	}
+	j.Auth = conn.Auth
+	j.Connections = nil
+	j.ConnectionMapping = nil
+	return j
+}
+
+type JiraRetry struct {
+	MaxRetries uint     `yaml:"maxRetries"`
+	BaseDelay  Duration `yaml:"baseDelay"`
+}
+
+// JiraStartupRetry is how long to wait for Jira to become reachable on
+// startup, before the configured projects, statuses, etc are checked.
+type JiraStartupRetry struct {
+	MaxRetries uint     `yaml:"maxRetries"`
+	Delay      Duration `yaml:"delay"`
+}
+
+// JiraOperationTimeouts are the timeouts per kind of Jira request, including
+// retries. A value of 0 means only [Jira.Timeout] applies.
+type JiraOperationTimeouts struct {
+	Search Duration
+	Create Duration
+	Update Duration
+}
+
+// JiraTransport is the connection pooling of the Jira HTTP client, where
+// zero values use the defaults of Go's standard HTTP transport.
+type JiraTransport struct {
+	MaxIdleConns        uint     `yaml:"maxIdleConns"`
+	MaxIdleConnsPerHost uint     `yaml:"maxIdleConnsPerHost"`
+	IdleConnTimeout     Duration `yaml:"idleConnTimeout"`
 }
 
 type JiraAuth struct {
@@ -108,25 +214,269 @@ type JiraAuth struct {
 // Jira Ticket type
 type JiraIssue struct {
 	Labels                 []string
+	LowercaseLabels        bool                `yaml:"lowercaseLabels"`
+	ManagedLabel           string              `yaml:"managedLabel"`
+	MarkerLabel            *Template           `yaml:"markerLabel"`
+	ProviderLabels         map[string][]string `yaml:"providerLabels"`
 	Status                 string
-	Description            string
+	PostCreateTransition   string    `yaml:"postCreateTransition"`
+	SearchStatuses         []string  `yaml:"searchStatuses"`
+	SearchTemplate         *Template `yaml:"searchTemplate"`
+	Summary                *Template
+	Description            *Template
+	ReleaseURLs            map[string]*Template `yaml:"releaseUrls"`
+	MaxSummaryLength       uint                 `yaml:"maxSummaryLength"`
 	Type                   string
+	ProviderTypes          map[string]string `yaml:"providerTypes"`
+	ProviderDisplayNames   map[string]string `yaml:"providerDisplayNames"`
+	SecurityType           string            `yaml:"securityType"`
+	ParentKey              string            `yaml:"parentKey"`
+	SubtaskType            string            `yaml:"subtaskType"`
+	Priority               string
+	SecurityPriority       string `yaml:"securityPriority"`
+	DueInDays              uint   `yaml:"dueInDays"`
+	SecurityDueInDays      uint   `yaml:"securityDueInDays"`
+	Assignee               string
+	Reporter               string
+	Watchers               []string
+	SecurityLevel          string    `yaml:"securityLevel"`
+	FixVersion             *Template `yaml:"fixVersion"`
+	CreateMissingVersions  bool      `yaml:"createMissingVersions"`
+	Components             []string
+	ProjectComponents      map[string][]string `yaml:"projectComponents"`
 	Project                string
-	ProjectNameCustomField uint `yaml:"projectNameCustomField"`
+	ProjectMapping         map[string]string `yaml:"projectMapping"`
+	ProjectNameCustomField uint              `yaml:"projectNameCustomField"`
+	ReuseExisting          bool              `yaml:"reuseExisting"`
+	UpdateMode             UpdateMode        `yaml:"updateMode"`
+	ProtectEditedSummaries bool              `yaml:"protectEditedSummaries"`
+	MatchStrategy          MatchStrategy     `yaml:"matchStrategy"`
+	UpdateLabels           bool              `yaml:"updateLabels"`
+	ReopenClosed           bool              `yaml:"reopenClosed"`
+	CloseDuplicates        bool              `yaml:"closeDuplicates"`
+	MergeDuplicates        bool              `yaml:"mergeDuplicates"`
+	CommentDuplicates      bool              `yaml:"commentDuplicates"`
+	DuplicateStatus        string            `yaml:"duplicateStatus"`
+	CustomFields           map[string]any    `yaml:"customFields"`
+	Environment            JiraIssueEnvironment
+	Security               JiraIssueSecurity
 
 	Comments JiraIssueComments
 }
 
+// DisplayProvider returns the friendly name of a newreleases.io provider,
+// e.g "PyPI" for "pypi", falling back to the provider name as-is.
+func (i JiraIssue) DisplayProvider(provider string) string {
+	if name, ok := i.ProviderDisplayNames[provider]; ok {
+		return name
+	}
+	return provider
+}
+
+// ProjectForProvider returns the Jira project key to create issues in for
+// a given newreleases.io provider (e.g "npm" or "pypi"), falling back to the
+// default project when there is no mapping for the provider.
+func (i JiraIssue) ProjectForProvider(provider string) string {
+	if project, ok := i.ProjectMapping[provider]; ok && project != "" {
+		return project
+	}
+	return i.Project
+}
+
+// ComponentsForProject returns the Jira components to set on issues created
+// in a given Jira project, falling back to the default components when there
+// is no mapping for the project.
+func (i JiraIssue) ComponentsForProject(projectKey string) []string {
+	if components, ok := i.ProjectComponents[projectKey]; ok {
+		return components
+	}
+	return i.Components
+}
+
+// AllProjects returns the default project key and all mapped project keys,
+// without duplicates.
+func (i JiraIssue) AllProjects() []string {
+	projects := []string{i.Project}
+	for _, project := range i.ProjectMapping {
+		if project != "" && !slices.Contains(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	slices.Sort(projects[1:])
+	return projects
+}
+
+// StatusesToSearch returns the statuses to search existing issues in, which
+// always includes the default status, as that is where issues are created,
+// and the status that issues are transitioned to after being created.
+func (i JiraIssue) StatusesToSearch() []string {
+	statuses := []string{i.Status}
+	for _, status := range util.Concat([]string{i.PostCreateTransition}, i.SearchStatuses) {
+		if status != "" && !slices.Contains(statuses, status) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// TypeForProvider returns the Jira issue type to create issues as for
+// a given newreleases.io provider, falling back to the default issue type
+// when there is no mapping for the provider.
+func (i JiraIssue) TypeForProvider(provider string) string {
+	if issueType, ok := i.ProviderTypes[provider]; ok && issueType != "" {
+		return issueType
+	}
+	return i.Type
+}
+
+// TypesByProject returns the Jira issue types that may be used when creating
+// issues in each project, based on the project and issue type mappings,
+// without duplicates.
+func (i JiraIssue) TypesByProject() map[string][]string {
+	types := map[string][]string{}
+	add := func(project, issueType string) {
+		if !slices.Contains(types[project], issueType) {
+			types[project] = append(types[project], issueType)
+		}
+	}
+	if i.ParentKey != "" {
+		for _, project := range i.AllProjects() {
+			add(project, i.SubtaskType)
+		}
+		return types
+	}
+	add(i.Project, i.Type)
+	for provider := range i.ProjectMapping {
+		add(i.ProjectForProvider(provider), i.TypeForProvider(provider))
+	}
+	for provider := range i.ProviderTypes {
+		add(i.ProjectForProvider(provider), i.TypeForProvider(provider))
+	}
+	if i.SecurityType != "" {
+		for _, project := range i.AllProjects() {
+			add(project, i.SecurityType)
+		}
+	}
+	for _, projectTypes := range types {
+		slices.Sort(projectTypes)
+	}
+	return types
+}
+
+// SecurityProjectTypes returns the Jira issue types that may be used when
+// creating issues in the [JiraIssueSecurity.Project], without duplicates.
+// These are never subtasks, as the parent issue is in another project.
+func (i JiraIssue) SecurityProjectTypes() []string {
+	if i.SecurityType != "" {
+		return []string{i.SecurityType}
+	}
+	types := []string{i.Type}
+	for provider := range i.ProviderTypes {
+		if issueType := i.TypeForProvider(provider); !slices.Contains(types, issueType) {
+			types = append(types, issueType)
+		}
+	}
+	slices.Sort(types)
+	return types
+}
+
+// JiraIssueEnvironment makes issues from different jelease deployments,
+// such as staging and production, distinguishable.
+type JiraIssueEnvironment struct {
+	Tag  string
+	Mode EnvironmentMode
+}
+
+// JiraIssueSecurity makes issues for releases that fix CVEs (vulnerabilities)
+// easier to find.
+type JiraIssueSecurity struct {
+	Label         bool // adds the "security" label
+	CVELabels     bool `yaml:"cveLabels"`     // adds a label per CVE, e.g "cve-2022-1234"
+	SummaryPrefix bool `yaml:"summaryPrefix"` // prefixes the summary with "[SECURITY]"
+	SummaryCVEs   bool `yaml:"summaryCves"`   // appends the CVE IDs to the summary
+
+	// Project, if set, is the Jira project key to create an additional issue
+	// in for releases with CVEs, which is linked to the regular issue.
+	Project  string
+	LinkType string `yaml:"linkType"`
+}
+
 type JiraIssueComments struct {
 	UpdatedIssue *Template `yaml:"updatedIssue"`
 	NoConfig     *Template `yaml:"noConfig"`
 	NoPatches    *Template `yaml:"noPatches"`
 	PRCreated    *Template `yaml:"prCreated"`
 	PRFailed     *Template `yaml:"prFailed"`
+	Duplicate    *Template `yaml:"duplicate"`
+	Superseded   *Template `yaml:"superseded"`
+}
+
+type Slack struct {
+	WebhookURL string `yaml:"webhookUrl" jsonschema_extras:"format=uri"`
+	Message    *Template
 }
 
 type HTTP struct {
-	Port uint16
+	Port              uint16
+	WebhookPath       string   `yaml:"webhookPath"`
+	HealthPath        string   `yaml:"healthPath"`
+	WebhookSecret     string   `yaml:"webhookSecret"`
+	SignatureHeader   string   `yaml:"signatureHeader"`
+	TimestampHeader   string   `yaml:"timestampHeader"`
+	StrictPayload     bool     `yaml:"strictPayload"`
+	AllowedIPs        []string `yaml:"allowedIps"`
+	TrustProxy        bool     `yaml:"trustProxy"`
+	AllowPartialBatch bool     `yaml:"allowPartialBatch"`
+	ShutdownTimeout   Duration `yaml:"shutdownTimeout"`
+	Debounce          Duration
+	Timeouts          HTTPTimeouts
+	RateLimit         HTTPRateLimit `yaml:"rateLimit"`
+	Queue             HTTPQueue
+	Trigger           HTTPTrigger
+	Validate          HTTPValidate
+	FailedPayloads    HTTPFailedPayloads `yaml:"failedPayloads"`
+	Metrics           HTTPMetrics
+}
+
+// HTTPFailedPayloads stores webhook payloads that failed to decode, for
+// debugging changes in the newreleases.io payload format.
+type HTTPFailedPayloads struct {
+	Dir         string
+	MaxFiles    uint `yaml:"maxFiles"`
+	MaxFileSize uint `yaml:"maxFileSize"`
+}
+
+// HTTPValidate allows validating webhook payloads and the issue templates
+// via the webhook's ?validate=true query parameter, without touching Jira.
+type HTTPValidate struct {
+	Enabled bool
+}
+
+type HTTPMetrics struct {
+	Enabled bool
+}
+
+type HTTPTrigger struct {
+	Enabled bool
+	Token   string
+}
+
+type HTTPQueue struct {
+	Enabled bool
+	Size    uint
+	Workers uint
+}
+
+type HTTPRateLimit struct {
+	Rate  float64
+	Burst uint
+}
+
+type HTTPTimeouts struct {
+	ReadHeader Duration `yaml:"readHeader"`
+	Read       Duration
+	Write      Duration
+	Idle       Duration
 }
 
 type Log struct {
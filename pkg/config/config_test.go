@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		project string
+		want    bool
+	}{
+		{
+			name:    "empty",
+			project: "foo",
+			want:    true,
+		},
+		{
+			name:    "allowed",
+			filter:  Filter{Allowlist: []string{"foo"}},
+			project: "foo",
+			want:    true,
+		},
+		{
+			name:    "not allowed",
+			filter:  Filter{Allowlist: []string{"foo"}},
+			project: "bar",
+			want:    false,
+		},
+		{
+			name:    "denied",
+			filter:  Filter{Denylist: []string{"foo"}},
+			project: "foo",
+			want:    false,
+		},
+		{
+			name:    "not denied",
+			filter:  Filter{Denylist: []string{"foo"}},
+			project: "bar",
+			want:    true,
+		},
+		{
+			name:    "allowlist takes precedence",
+			filter:  Filter{Allowlist: []string{"foo"}, Denylist: []string{"foo"}},
+			project: "foo",
+			want:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.filter.Allows(tc.project)
+			if got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestJiraConnectionName(t *testing.T) {
+	cfg := Jira{
+		ConnectionMapping: map[string]string{
+			"npm":       "frontend",
+			"neuvector": "platform",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		project  string
+		want     string
+	}{
+		{name: "by provider", provider: "npm", project: "react", want: "frontend"},
+		{name: "by project", provider: "github", project: "neuvector", want: "platform"},
+		{name: "project takes precedence", provider: "npm", project: "neuvector", want: "platform"},
+		{name: "default", provider: "pypi", project: "django", want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cfg.ConnectionName(tc.provider, tc.project)
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestJiraIssueTypesByProject(t *testing.T) {
+	issue := JiraIssue{
+		Project:        "OP",
+		Type:           "Task",
+		ProjectMapping: map[string]string{"npm": "FRONT"},
+		ProviderTypes:  map[string]string{"docker": "Story", "npm": "Story"},
+		SecurityType:   "Bug",
+	}
+	want := map[string][]string{
+		"OP":    {"Bug", "Story", "Task"},
+		"FRONT": {"Bug", "Story"},
+	}
+	got := issue.TypesByProject()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestJiraIssueTypesByProject_parentKey(t *testing.T) {
+	issue := JiraIssue{
+		Project:       "OP",
+		Type:          "Task",
+		ProviderTypes: map[string]string{"docker": "Story"},
+		SecurityType:  "Bug",
+		ParentKey:     "OP-123",
+		SubtaskType:   "Sub-task",
+	}
+	want := map[string][]string{
+		"OP": {"Sub-task"},
+	}
+	got := issue.TypesByProject()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestJiraIssueSecurityProjectTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue JiraIssue
+		want  []string
+	}{
+		{
+			name:  "security type",
+			issue: JiraIssue{Type: "Task", ProviderTypes: map[string]string{"docker": "Story"}, SecurityType: "Bug"},
+			want:  []string{"Bug"},
+		},
+		{
+			name:  "provider types",
+			issue: JiraIssue{Type: "Task", ProviderTypes: map[string]string{"docker": "Story", "npm": "Story"}},
+			want:  []string{"Story", "Task"},
+		},
+		{
+			name:  "ignores subtask type",
+			issue: JiraIssue{Type: "Task", ParentKey: "OP-123", SubtaskType: "Sub-task"},
+			want:  []string{"Task"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.issue.SecurityProjectTypes()
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestJiraIssueStatusesToSearch(t *testing.T) {
+	issue := JiraIssue{
+		Status:               "Open",
+		PostCreateTransition: "Backlog",
+		SearchStatuses:       []string{"In Progress", "Backlog", ""},
+	}
+	want := []string{"Open", "Backlog", "In Progress"}
+	got := issue.StatusesToSearch()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
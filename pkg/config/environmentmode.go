@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// EnvironmentMode decides where the environment tag is applied on issues.
+type EnvironmentMode string
+
+const (
+	EnvironmentModeSummary EnvironmentMode = "summary"
+	EnvironmentModeLabel   EnvironmentMode = "label"
+	EnvironmentModeBoth    EnvironmentMode = "both"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := EnvironmentModeBoth
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f EnvironmentMode) String() string {
+	return string(f)
+}
+
+func (f *EnvironmentMode) Set(value string) error {
+	switch EnvironmentMode(value) {
+	case EnvironmentModeSummary:
+		*f = EnvironmentModeSummary
+	case EnvironmentModeLabel:
+		*f = EnvironmentModeLabel
+	case EnvironmentModeBoth:
+		*f = EnvironmentModeBoth
+	default:
+		return fmt.Errorf("unknown environment mode: %q, must be one of: summary, label, both", value)
+	}
+	return nil
+}
+
+func (f *EnvironmentMode) Type() string {
+	return "mode"
+}
+
+func (f *EnvironmentMode) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (EnvironmentMode) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:  "string",
+		Title: "Jira issue environment tag mode",
+		Enum: []any{
+			EnvironmentModeSummary,
+			EnvironmentModeLabel,
+			EnvironmentModeBoth,
+		},
+	}
+}
+
+// PrefixesSummary returns true if the environment tag should be added as
+// a prefix to the issue summary.
+func (f EnvironmentMode) PrefixesSummary() bool {
+	return f != EnvironmentModeLabel
+}
+
+// AddsLabel returns true if the environment tag should be added as a label.
+func (f EnvironmentMode) AddsLabel() bool {
+	return f != EnvironmentModeSummary
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+type JiraAPIVersion string
+
+const (
+	// JiraAPIVersionV2 uses the Jira REST API v2, where descriptions are
+	// plain text in Jira's wiki markup. Supported by Jira Server, Data Center,
+	// and Cloud.
+	JiraAPIVersionV2 JiraAPIVersion = "v2"
+	// JiraAPIVersionV3 uses the Jira REST API v3, where descriptions are
+	// sent in Atlassian Document Format (ADF). Only supported by Jira Cloud.
+	JiraAPIVersionV3 JiraAPIVersion = "v3"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := JiraAPIVersionV2
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f JiraAPIVersion) String() string {
+	return string(f)
+}
+
+func (f *JiraAPIVersion) Set(value string) error {
+	switch JiraAPIVersion(value) {
+	case JiraAPIVersionV2, JiraAPIVersionV3:
+		*f = JiraAPIVersion(value)
+	default:
+		return fmt.Errorf("unknown Jira API version: %q, must be one of: v2, v3", value)
+	}
+	return nil
+}
+
+func (f *JiraAPIVersion) Type() string {
+	return "version"
+}
+
+func (f *JiraAPIVersion) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (JiraAPIVersion) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:  "string",
+		Title: "Jira API version",
+		Enum: []any{
+			JiraAPIVersionV2,
+			JiraAPIVersionV3,
+		},
+	}
+}
@@ -28,7 +28,11 @@ import (
 type JiraAuthType string
 
 const (
-	JiraAuthTypePAT   JiraAuthType = "pat"
+	// JiraAuthTypePAT uses a personal access token (PAT) as a bearer token,
+	// as used by Jira Server and Data Center. Alias: "bearer"
+	JiraAuthTypePAT JiraAuthType = "pat"
+	// JiraAuthTypeToken uses HTTP basic auth with a username and an API
+	// token (or password), as used by Jira Cloud. Alias: "basic"
 	JiraAuthTypeToken JiraAuthType = "token"
 )
 
@@ -45,13 +49,13 @@ func (f JiraAuthType) String() string {
 }
 
 func (f *JiraAuthType) Set(value string) error {
-	switch JiraAuthType(value) {
-	case JiraAuthTypePAT:
+	switch value {
+	case string(JiraAuthTypePAT), "bearer":
 		*f = JiraAuthTypePAT
-	case JiraAuthTypeToken:
+	case string(JiraAuthTypeToken), "basic":
 		*f = JiraAuthTypeToken
 	default:
-		return fmt.Errorf("unknown auth type: %q, must be one of: pat, token", value)
+		return fmt.Errorf("unknown auth type: %q, must be one of: pat, token (aliases: bearer, basic)", value)
 	}
 	return nil
 }
@@ -71,6 +75,8 @@ func (JiraAuthType) JSONSchema() *jsonschema.Schema {
 		Enum: []any{
 			JiraAuthTypePAT,
 			JiraAuthTypeToken,
+			"bearer",
+			"basic",
 		},
 	}
 }
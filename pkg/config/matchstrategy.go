@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// MatchStrategy decides which existing Jira issues are updated for a new
+// release, instead of creating a new issue.
+type MatchStrategy string
+
+const (
+	// MatchStrategyProject uses one issue per project, regardless of version.
+	MatchStrategyProject MatchStrategy = "project"
+	// MatchStrategyProjectMajor uses one issue per major version of a project.
+	MatchStrategyProjectMajor MatchStrategy = "project-major"
+	// MatchStrategyProjectMinor uses one issue per minor version of a project.
+	MatchStrategyProjectMinor MatchStrategy = "project-minor"
+	// MatchStrategyExactVersion uses one issue per version of a project.
+	MatchStrategyExactVersion MatchStrategy = "exact-version"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := MatchStrategyProject
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f MatchStrategy) String() string {
+	return string(f)
+}
+
+func (f *MatchStrategy) Set(value string) error {
+	switch MatchStrategy(value) {
+	case MatchStrategyProject, MatchStrategyProjectMajor, MatchStrategyProjectMinor, MatchStrategyExactVersion:
+		*f = MatchStrategy(value)
+	default:
+		return fmt.Errorf("unknown match strategy: %q, must be one of: project, project-major, project-minor, exact-version", value)
+	}
+	return nil
+}
+
+func (f *MatchStrategy) Type() string {
+	return "strategy"
+}
+
+func (f *MatchStrategy) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (MatchStrategy) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:  "string",
+		Title: "Jira issue match strategy",
+		Enum: []any{
+			MatchStrategyProject,
+			MatchStrategyProjectMajor,
+			MatchStrategyProjectMinor,
+			MatchStrategyExactVersion,
+		},
+	}
+}
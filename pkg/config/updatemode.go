@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+type UpdateMode string
+
+const (
+	UpdateModeSummary UpdateMode = "summary"
+	UpdateModeComment UpdateMode = "comment"
+	UpdateModeBoth    UpdateMode = "both"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := UpdateModeComment
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f UpdateMode) String() string {
+	return string(f)
+}
+
+func (f *UpdateMode) Set(value string) error {
+	switch UpdateMode(value) {
+	case UpdateModeSummary:
+		*f = UpdateModeSummary
+	case UpdateModeComment:
+		*f = UpdateModeComment
+	case UpdateModeBoth:
+		*f = UpdateModeBoth
+	default:
+		return fmt.Errorf("unknown update mode: %q, must be one of: summary, comment, both", value)
+	}
+	return nil
+}
+
+func (f *UpdateMode) Type() string {
+	return "mode"
+}
+
+func (f *UpdateMode) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (UpdateMode) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:  "string",
+		Title: "Jira issue update mode",
+		Enum: []any{
+			UpdateModeSummary,
+			UpdateModeComment,
+			UpdateModeBoth,
+		},
+	}
+}
+
+// UpdatesSummary returns true if the issue summary should be updated.
+func (f UpdateMode) UpdatesSummary() bool {
+	return f != UpdateModeComment
+}
+
+// UpdatesComment returns true if a comment should be added to the issue.
+func (f UpdateMode) UpdatesComment() bool {
+	return f != UpdateModeSummary
+}
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+// ValidationErrors is a list of all problems found in a config.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid config:\n  - " + strings.Join(msgs, "\n  - ")
+}
+
+// Validate checks the config for missing or malformed fields and conflicting
+// options, so that misconfigurations are found at startup instead of when the
+// first webhook arrives. All problems are returned as [ValidationErrors].
+func (c Config) Validate() error {
+	var errs ValidationErrors
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if len(c.Filter.Allowlist) > 0 && len(c.Filter.Denylist) > 0 {
+		addErr("filter: allowlist and denylist cannot both be set")
+	}
+
+	for name, path := range map[string]string{
+		"http.webhookPath": c.HTTP.WebhookPath,
+		"http.healthPath":  c.HTTP.HealthPath,
+	} {
+		if !strings.HasPrefix(path, "/") {
+			addErr("%s: invalid HTTP path %q: must start with a slash", name, path)
+		}
+	}
+
+	if c.Jira.URL == "" {
+		addErr("jira.url: must be set")
+	}
+
+	for key, name := range c.Jira.ConnectionMapping {
+		if _, ok := c.Jira.Connections[name]; !ok {
+			addErr("jira.connectionMapping.%s: Jira connection %q not found in jira.connections", key, name)
+		}
+	}
+	for name, conn := range c.Jira.Connections {
+		if conn.URL == "" {
+			addErr("jira.connections.%s.url: must be set", name)
+		}
+	}
+
+	issue := c.Jira.Issue
+	for name, value := range map[string]string{
+		"jira.issue.project": issue.Project,
+		"jira.issue.status":  issue.Status,
+		"jira.issue.type":    issue.Type,
+	} {
+		if value == "" {
+			addErr("%s: must be set", name)
+		}
+	}
+
+	for _, label := range issue.Labels {
+		if isTemplatedLabel(label) {
+			var tmpl Template
+			if err := tmpl.Set(label); err != nil {
+				addErr("jira.issue.labels: invalid label template %q: %s", label, err)
+			}
+			continue
+		}
+		if !isValidLabel(label) {
+			addErr("jira.issue.labels: invalid label %q: must be non-empty and not contain spaces", label)
+		}
+	}
+	if issue.ManagedLabel != "" && !isValidLabel(issue.ManagedLabel) {
+		addErr("jira.issue.managedLabel: invalid label %q: must not contain spaces", issue.ManagedLabel)
+	}
+	for provider, labels := range issue.ProviderLabels {
+		for _, label := range labels {
+			if isTemplatedLabel(label) {
+				var tmpl Template
+				if err := tmpl.Set(label); err != nil {
+					addErr("jira.issue.providerLabels.%s: invalid label template %q: %s", provider, label, err)
+				}
+				continue
+			}
+			if !isValidLabel(label) {
+				addErr("jira.issue.providerLabels.%s: invalid label %q: must be non-empty and not contain spaces", provider, label)
+			}
+		}
+	}
+
+	if env := issue.Environment; env.Tag != "" && env.Mode.AddsLabel() && !isValidLabel(env.Tag) {
+		addErr("jira.issue.environment.tag: invalid label %q: must not contain spaces when used as label", env.Tag)
+	}
+
+	for provider, project := range issue.ProjectMapping {
+		if project == "" {
+			addErr("jira.issue.projectMapping.%s: project key must be set", provider)
+		}
+	}
+	allProjects := issue.AllProjects()
+	for project := range issue.ProjectComponents {
+		if !slices.Contains(allProjects, project) {
+			addErr("jira.issue.projectComponents.%s: project is not used by jira.issue.project nor jira.issue.projectMapping", project)
+		}
+	}
+
+	if issue.ParentKey != "" {
+		if issue.SubtaskType == "" {
+			addErr("jira.issue.subtaskType: must be set when parentKey is set")
+		}
+		// Subtasks must be in the same project as their parent, and the
+		// project key is the part of the issue key before the number
+		parentProject := issue.ParentKey
+		if i := strings.LastIndexByte(parentProject, '-'); i != -1 {
+			parentProject = parentProject[:i]
+		}
+		for _, project := range allProjects {
+			if project != parentProject {
+				addErr("jira.issue.parentKey: parent issue %q must be in the same project as created issues, but issues are created in project %q", issue.ParentKey, project)
+			}
+		}
+	}
+
+	if issue.CloseDuplicates {
+		if issue.DuplicateStatus == "" {
+			addErr("jira.issue.duplicateStatus: must be set when closeDuplicates is enabled")
+		}
+		if issue.ManagedLabel == "" {
+			addErr("jira.issue.managedLabel: must be set when closeDuplicates is enabled")
+		}
+	} else if issue.MergeDuplicates {
+		addErr("jira.issue.mergeDuplicates: requires closeDuplicates to be enabled")
+	}
+
+	if issue.Security.Project != "" && issue.Security.LinkType == "" {
+		addErr("jira.issue.security.linkType: must be set when jira.issue.security.project is set")
+	}
+
+	if c.Slack.WebhookURL != "" && c.Slack.Message == nil {
+		addErr("slack.message: template must be set when slack.webhookUrl is set")
+	}
+
+	for _, cidr := range c.HTTP.AllowedIPs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			addErr("http.allowedIps: invalid CIDR %q: use e.g 192.0.2.1/32 for a single IP", cidr)
+		}
+	}
+
+	if c.HTTP.Trigger.Enabled && c.HTTP.Trigger.Token == "" {
+		addErr("http.trigger.token: must be set when the trigger endpoint is enabled")
+	}
+
+	for name, tmpl := range map[string]*Template{
+		"jira.issue.comments.updatedIssue": issue.Comments.UpdatedIssue,
+		"jira.issue.comments.noConfig":     issue.Comments.NoConfig,
+		"jira.issue.comments.noPatches":    issue.Comments.NoPatches,
+		"jira.issue.comments.prCreated":    issue.Comments.PRCreated,
+		"jira.issue.comments.prFailed":     issue.Comments.PRFailed,
+		"jira.issue.comments.duplicate":    issue.Comments.Duplicate,
+		"jira.issue.comments.superseded":   issue.Comments.Superseded,
+	} {
+		if tmpl == nil {
+			addErr("%s: template must be set", name)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.SortFunc(errs, func(a, b error) bool {
+		return a.Error() < b.Error()
+	})
+	return errs
+}
+
+// isTemplatedLabel returns true if the label contains template actions,
+// which are only checked for spaces after rendering.
+func isTemplatedLabel(label string) bool {
+	return strings.Contains(label, "{{")
+}
+
+func isValidLabel(label string) bool {
+	return label != "" && strings.IndexFunc(label, unicode.IsSpace) == -1
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func validConfig() Config {
+	var tmpl Template
+	tmpl.Set("comment")
+	return Config{
+		Jira: Jira{
+			URL: "https://jira.example.com",
+			Issue: JiraIssue{
+				Project: "OP",
+				Status:  "Backlog",
+				Type:    "Story",
+				Labels:  []string{"jelease"},
+				Comments: JiraIssueComments{
+					UpdatedIssue: &tmpl,
+					NoConfig:     &tmpl,
+					NoPatches:    &tmpl,
+					PRCreated:    &tmpl,
+					PRFailed:     &tmpl,
+					Duplicate:    &tmpl,
+					Superseded:   &tmpl,
+				},
+			},
+		},
+		HTTP: HTTP{
+			WebhookPath: "/",
+			HealthPath:  "/healthz",
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "allowlist and denylist",
+			modify: func(c *Config) {
+				c.Filter.Allowlist = []string{"foo"}
+				c.Filter.Denylist = []string{"bar"}
+			},
+			want: []string{"filter: allowlist and denylist cannot both be set"},
+		},
+		{
+			name: "multiple errors",
+			modify: func(c *Config) {
+				c.HTTP.WebhookPath = "webhook"
+				c.Jira.Issue.Labels = []string{"my label"}
+				c.Jira.Issue.Comments.PRFailed = nil
+			},
+			want: []string{
+				`http.webhookPath: invalid HTTP path "webhook": must start with a slash`,
+				`jira.issue.comments.prFailed: template must be set`,
+				`jira.issue.labels: invalid label "my label": must be non-empty and not contain spaces`,
+			},
+		},
+		{
+			name: "templated labels",
+			modify: func(c *Config) {
+				c.Jira.Issue.Labels = []string{"provider-{{ .Provider }}", "broken-{{ .Provider"}
+			},
+			want: []string{`jira.issue.labels: invalid label template "broken-{{ .Provider": template: :1: unclosed action`},
+		},
+		{
+			name: "unknown project components",
+			modify: func(c *Config) {
+				c.Jira.Issue.ProjectComponents = map[string][]string{"OTHER": {"Backend"}}
+			},
+			want: []string{"jira.issue.projectComponents.OTHER: project is not used by jira.issue.project nor jira.issue.projectMapping"},
+		},
+		{
+			name: "merge duplicates without closing",
+			modify: func(c *Config) {
+				c.Jira.Issue.MergeDuplicates = true
+			},
+			want: []string{"jira.issue.mergeDuplicates: requires closeDuplicates to be enabled"},
+		},
+		{
+			name: "security project without link type",
+			modify: func(c *Config) {
+				c.Jira.Issue.Security.Project = "SEC"
+			},
+			want: []string{"jira.issue.security.linkType: must be set when jira.issue.security.project is set"},
+		},
+		{
+			name: "allowed IPs",
+			modify: func(c *Config) {
+				c.HTTP.AllowedIPs = []string{"192.0.2.0/24", "2001:db8::/32", "192.0.2.1"}
+			},
+			want: []string{`http.allowedIps: invalid CIDR "192.0.2.1": use e.g 192.0.2.1/32 for a single IP`},
+		},
+		{
+			name: "parent in same project",
+			modify: func(c *Config) {
+				c.Jira.Issue.ParentKey = "OP-123"
+				c.Jira.Issue.SubtaskType = "Sub-task"
+			},
+		},
+		{
+			name: "parent in other project",
+			modify: func(c *Config) {
+				c.Jira.Issue.ParentKey = "OP-123"
+				c.Jira.Issue.SubtaskType = "Sub-task"
+				c.Jira.Issue.ProjectMapping = map[string]string{"npm": "FRONT"}
+			},
+			want: []string{`jira.issue.parentKey: parent issue "OP-123" must be in the same project as created issues, but issues are created in project "FRONT"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.modify(&cfg)
+			err := cfg.Validate()
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("want no error, got: %s", err)
+				}
+				return
+			}
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("want ValidationErrors, got: %#v", err)
+			}
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if strings.Join(tc.want, "\n") != strings.Join(got, "\n") {
+				t.Errorf("want:\n%s\ngot:\n%s", strings.Join(tc.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+import "strings"
+
+// adfDocument builds a minimal Atlassian Document Format (ADF) document out
+// of plain text, as required for descriptions in Jira Cloud's REST API v3.
+// Paragraphs are separated by blank lines, and single newlines are kept as
+// hard line breaks.
+//
+// Uses maps instead of structs, as go-jira serializes the unknown fields
+// using its own struct tags.
+func adfDocument(text string) map[string]any {
+	var paragraphs []any
+	for _, block := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		block = strings.Trim(block, "\n")
+		if block == "" {
+			continue
+		}
+		var content []any
+		for i, line := range strings.Split(block, "\n") {
+			if i > 0 {
+				content = append(content, map[string]any{"type": "hardBreak"})
+			}
+			if line != "" {
+				content = append(content, map[string]any{"type": "text", "text": line})
+			}
+		}
+		paragraphs = append(paragraphs, map[string]any{
+			"type":    "paragraph",
+			"content": content,
+		})
+	}
+	if paragraphs == nil {
+		paragraphs = []any{}
+	}
+	return map[string]any{
+		"version": 1,
+		"type":    "doc",
+		"content": paragraphs,
+	}
+}
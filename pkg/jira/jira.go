@@ -18,29 +18,65 @@
 package jira
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/metrics"
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/andygrunwald/go-jira"
 	"github.com/rs/zerolog/log"
 	"github.com/trivago/tgo/tcontainer"
+	"golang.org/x/exp/slices"
 )
 
 type Client interface {
+	Ping(ctx context.Context) error
 	ProjectMustExist(projectKey string) error
+	IssueMustExist(issueKey string) error
 	StatusMustExist(statusName string) error
-	FindIssuesForPackage(packageName string) ([]Issue, error)
-	UpdateIssueSummary(issueRef IssueRef, newSummary string) error
-	CreateIssue(issue Issue) (IssueRef, error)
-	CreateIssueComment(issueRef IssueRef, newComment string) error
+	UserMustExist(user string) error
+	PriorityMustExist(priorityName string) error
+	ComponentsMustExist(projectKey string, componentNames []string) error
+	IssueTypeMustExist(projectKey, issueTypeName string) error
+	WorkflowStatusMustExist(projectKey, issueTypeName, statusName string) error
+	WorkflowCreatedIssueStatuses(projectKey, issueTypeName string) (CreatedIssueStatuses, error)
+	PermissionsMustExist(projectKey string, permissions []string) error
+	SecurityLevelMustExist(projectKey, levelName string) error
+	LinkTypeMustExist(linkType string) error
+	FindIssuesForPackage(ctx context.Context, projectKey, packageName, matchLabel string) ([]Issue, error)
+	FindIssuesWithJQL(ctx context.Context, jql string) ([]Issue, error)
+	FindIssueWithLabel(ctx context.Context, label string) (Issue, bool, error)
+	UpdateIssueSummary(ctx context.Context, issueRef IssueRef, newSummary string) error
+	AddIssueLabels(ctx context.Context, issueRef IssueRef, labels []string) error
+	AddIssueWatcher(ctx context.Context, issueRef IssueRef, user string) error
+	LinkIssues(ctx context.Context, linkType string, inward, outward IssueRef) error
+	CreateIssue(ctx context.Context, issue Issue) (IssueRef, error)
+	CreateIssueComment(ctx context.Context, issueRef IssueRef, newComment string) error
+	GetIssueComments(ctx context.Context, issueRef IssueRef) ([]Comment, error)
+	TransitionIssue(ctx context.Context, issueRef IssueRef, statusName string) error
+}
+
+// Comment is a comment on a Jira issue.
+type Comment struct {
+	Author  string // display name of the author
+	Created time.Time
+	Body    string
+	// Restricted is true if the comment is only visible to some users,
+	// such as members of a role or group.
+	Restricted bool
 }
 
 type IssueRef struct {
@@ -56,9 +92,30 @@ type Issue struct {
 	Labels      []string
 	ProjectKey  string
 	TypeName    string
+	Priority    string
+	Assignee    string
+	Reporter    string
+	FixVersion  string
+	// SecurityLevel is the name of the issue security level
+	SecurityLevel string
+	Components    []string
+	DueDate       time.Time // only the date is sent to Jira, zero means unset
+	ParentKey     string    // creates the issue as a subtask of this issue
+	Created       time.Time
+	Status        string
+	StatusKey     string // key of the status category, e.g "done"
 
 	PackageName        string
 	PackageNameFieldID uint
+
+	// CustomFields are additional fields to set on created issues,
+	// keyed by field ID, e.g "customfield_10050".
+	CustomFields map[string]any
+}
+
+// IsClosed returns true if the issue's status is in the "done" category.
+func (i Issue) IsClosed() bool {
+	return i.StatusKey == jira.StatusCategoryComplete
 }
 
 func (i Issue) IssueRef() IssueRef {
@@ -70,6 +127,7 @@ func (i Issue) IssueRef() IssueRef {
 
 func newIssue(issue jira.Issue, pkgCustomFieldID uint) Issue {
 	fields := util.Deref(issue.Fields, jira.IssueFields{})
+	status := util.Deref(fields.Status, jira.Status{})
 	var pkgName string
 	if pkgCustomFieldID != 0 {
 		if str, ok := fields.Unknowns[customFieldName(pkgCustomFieldID)].(string); ok {
@@ -85,6 +143,10 @@ func newIssue(issue jira.Issue, pkgCustomFieldID uint) Issue {
 		Summary:     fields.Summary,
 		Description: fields.Description,
 		Labels:      fields.Labels,
+		ProjectKey:  fields.Project.Key,
+		Created:     time.Time(fields.Created),
+		Status:      status.Name,
+		StatusKey:   status.StatusCategory.Key,
 
 		PackageName:        pkgName,
 		PackageNameFieldID: pkgCustomFieldID,
@@ -97,15 +159,43 @@ func (i Issue) rawIssue() jira.Issue {
 
 	if i.PackageName != "" {
 		if i.PackageNameFieldID == 0 {
-			labels = append(labels, i.PackageName)
+			labels = util.Concat(labels, []string{i.PackageName})
 		} else {
 			extraFields = tcontainer.MarshalMap{
 				customFieldName(i.PackageNameFieldID): i.PackageName,
 			}
 		}
 	}
+	for field, value := range i.CustomFields {
+		if extraFields == nil {
+			extraFields = tcontainer.MarshalMap{}
+		}
+		extraFields[field] = value
+	}
+	if i.SecurityLevel != "" {
+		// go-jira has no typed field for the issue security level
+		if extraFields == nil {
+			extraFields = tcontainer.MarshalMap{}
+		}
+		extraFields["security"] = map[string]string{"name": i.SecurityLevel}
+	}
+	var priority *jira.Priority
+	if i.Priority != "" {
+		priority = &jira.Priority{Name: i.Priority}
+	}
+	var components []*jira.Component
+	for _, name := range i.Components {
+		components = append(components, &jira.Component{Name: name})
+	}
+	var parent *jira.Parent
+	if i.ParentKey != "" {
+		parent = &jira.Parent{Key: i.ParentKey}
+	}
 	return jira.Issue{
 		Fields: &jira.IssueFields{
+			// Date is serialized as "2006-01-02" as Jira expects,
+			// and is omitted when zero
+			Duedate:     jira.Date(i.DueDate),
 			Description: i.Description,
 			Project: jira.Project{
 				Key: i.ProjectKey,
@@ -113,9 +203,12 @@ func (i Issue) rawIssue() jira.Issue {
 			Type: jira.IssueType{
 				Name: i.TypeName,
 			},
-			Labels:   i.Labels,
-			Summary:  i.Summary,
-			Unknowns: extraFields,
+			Labels:     labels,
+			Summary:    i.Summary,
+			Priority:   priority,
+			Components: components,
+			Parent:     parent,
+			Unknowns:   extraFields,
 		},
 	}
 }
@@ -132,27 +225,41 @@ type client struct {
 	raw *jira.Client
 }
 
+// AppVersion is the version of jelease, sent in the User-Agent header of
+// Jira requests. Set by the cmd package on startup.
+var AppVersion = "unknown"
+
 func New(cfg *config.Jira) (Client, error) {
 	var httpClient *http.Client
-	tlsConfig := tls.Config{InsecureSkipVerify: cfg.SkipCertVerify}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = newTransport(&cfg.Transport, tlsConfig)
+	if cfg.UserAgent != "" {
+		transport = &userAgentTransport{
+			userAgent: cfg.UserAgent + "/" + AppVersion,
+			transport: transport,
+		}
+	}
 
 	switch cfg.Auth.Type {
 	case config.JiraAuthTypePAT:
 		httpClient = (&jira.PATAuthTransport{
 			Token:     cfg.Auth.Token,
-			Transport: &http.Transport{TLSClientConfig: &tlsConfig},
+			Transport: transport,
 		}).Client()
 	case config.JiraAuthTypeToken:
 		httpClient = (&jira.BasicAuthTransport{
 			Username:  cfg.Auth.User,
 			Password:  cfg.Auth.Token,
-			Transport: &http.Transport{TLSClientConfig: &tlsConfig},
+			Transport: transport,
 		}).Client()
 	default:
 		return nil, fmt.Errorf("invalid Jira auth type %q", cfg.Auth.Type)
 	}
 
-	httpClient.Timeout = 10 * time.Second
+	httpClient.Timeout = clientTimeout(cfg)
 	jiraClient, err := jira.NewClient(httpClient, cfg.URL)
 	if err != nil {
 		return nil, err
@@ -164,18 +271,113 @@ func New(cfg *config.Jira) (Client, error) {
 	}, nil
 }
 
+// clientTimeout returns the largest of the configured timeouts, used as the
+// HTTP client timeout so it never cuts a request short before the context
+// of the call times out, which is reported as 504 Gateway Timeout instead.
+// It still bounds the calls that are made without a context, such as the
+// checks on startup. A value of 0 means no timeout.
+func clientTimeout(cfg *config.Jira) time.Duration {
+	timeout := cfg.Timeout.Duration()
+	for _, opTimeout := range []config.Duration{
+		cfg.OperationTimeouts.Search,
+		cfg.OperationTimeouts.Create,
+		cfg.OperationTimeouts.Update,
+	} {
+		if opTimeout.Duration() > timeout {
+			timeout = opTimeout.Duration()
+		}
+	}
+	return timeout
+}
+
+// newTransport returns the HTTP transport for the Jira client, based on Go's
+// default transport, with the connection pooling settings that are set.
+func newTransport(cfg *config.JiraTransport, tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = int(cfg.MaxIdleConns)
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = int(cfg.MaxIdleConnsPerHost)
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout.Duration()
+	}
+	return transport
+}
+
+// userAgentTransport sets the User-Agent header on all requests, so Jira
+// admins can attribute the API traffic to jelease.
+type userAgentTransport struct {
+	userAgent string
+	transport http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper must not modify the request, so it's cloned
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.transport.RoundTrip(req)
+}
+
+// newTLSConfig returns the TLS config for the Jira HTTP client.
+//
+// Skipping certificate verification makes the connection vulnerable to
+// man-in-the-middle attacks, where anyone on the network path can read and
+// alter the traffic, including the Jira credentials sent on every request.
+// Trusting the Jira server's CA via caCertFile keeps verification in place
+// and should be preferred.
+func newTLSConfig(cfg *config.Jira) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.SkipCertVerify}
+	if cfg.SkipCertVerify {
+		log.Warn().
+			Str("url", cfg.URL).
+			Msg("TLS certificate verification is DISABLED for Jira. This is insecure and should never be used in production.")
+	}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("read Jira CA cert file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read Jira CA cert file %q: no PEM-encoded certificates found", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func (c *client) Ping(ctx context.Context) error {
+	_, resp, err := c.raw.User.GetSelfWithContext(ctx)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("get current Jira user: status %s: %w", resp.Status, err)
+		}
+		return fmt.Errorf("get current Jira user: %w", err)
+	}
+	return nil
+}
+
+func (c *client) IssueMustExist(issueKey string) error {
+	_, response, err := c.raw.Issue.Get(issueKey, &jira.GetQueryOptions{Fields: "summary"})
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("issue %q not found", issueKey)
+		}
+		return c.responseError("error response from Jira when retrieving issue", response, err)
+	}
+	return nil
+}
+
 func (c *client) ProjectMustExist(projectKey string) error {
 	allProjects, response, err := c.raw.Project.GetList()
 	if err != nil {
-		errCtx := errors.New("error response from Jira when retrieving project list")
-		if response != nil {
-			body, readErr := io.ReadAll(response.Body)
-			if readErr != nil {
-				return fmt.Errorf("%v: %w. Failed to decode response body: %v", errCtx, err, readErr)
-			}
-			return fmt.Errorf("%v: %w. Response body: %v", errCtx, err, string(body))
-		}
-		return fmt.Errorf("%v: %w", errCtx, err)
+		return c.responseError("error response from Jira when retrieving project list", response, err)
 	}
 	for _, project := range *allProjects {
 		if project.Key == projectKey {
@@ -188,15 +390,7 @@ func (c *client) ProjectMustExist(projectKey string) error {
 func (c *client) StatusMustExist(statusName string) error {
 	allStatuses, response, err := c.raw.Status.GetAllStatuses()
 	if err != nil {
-		errCtx := errors.New("error response from Jira when retrieving status list: %+v")
-		if response != nil {
-			body, readErr := io.ReadAll(response.Body)
-			if readErr != nil {
-				return fmt.Errorf("%v: %w. Failed to decode response body: %v", errCtx, err, readErr)
-			}
-			return fmt.Errorf("%v: %w. Response body: %v", errCtx, err, string(body))
-		}
-		return fmt.Errorf("%v: %w", errCtx, err)
+		return c.responseError("error response from Jira when retrieving status list", response, err)
 	}
 	for _, status := range allStatuses {
 		if status.Name == statusName {
@@ -211,17 +405,404 @@ func (c *client) StatusMustExist(statusName string) error {
 		statusName, strings.Join(statusNames, ", "))
 }
 
-func (c *client) FindIssuesForPackage(packageName string) ([]Issue, error) {
-	query := newJiraIssueSearchQuery(c.cfg.Issue.Status, packageName, c.cfg.Issue.ProjectNameCustomField)
-	rawIssues, resp, err := c.raw.Issue.Search(query, &jira.SearchOptions{})
+func (c *client) PriorityMustExist(priorityName string) error {
+	allPriorities, response, err := c.raw.Priority.GetList()
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving priority list", response, err)
+	}
+	var priorityNames []string
+	for _, priority := range allPriorities {
+		if priority.Name == priorityName {
+			return nil
+		}
+		priorityNames = append(priorityNames, priority.Name)
+	}
+	return fmt.Errorf("priority %q not found in Jira, but has: %v",
+		priorityName, strings.Join(priorityNames, ", "))
+}
+
+func (c *client) ComponentsMustExist(projectKey string, componentNames []string) error {
+	project, response, err := c.raw.Project.Get(projectKey)
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving project", response, err)
+	}
+	var existingNames []string
+	for _, component := range project.Components {
+		existingNames = append(existingNames, component.Name)
+	}
+	for _, name := range componentNames {
+		if !slices.Contains(existingNames, name) {
+			return fmt.Errorf("component %q not found in project %q, but has: %v",
+				name, projectKey, strings.Join(existingNames, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *client) IssueTypeMustExist(projectKey, issueTypeName string) error {
+	project, response, err := c.raw.Project.Get(projectKey)
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving project", response, err)
+	}
+	var typeNames []string
+	for _, issueType := range project.IssueTypes {
+		if strings.EqualFold(issueType.Name, issueTypeName) {
+			return nil
+		}
+		typeNames = append(typeNames, issueType.Name)
+	}
+	return fmt.Errorf("issue type %q not found in project %q, but has: %v",
+		issueTypeName, projectKey, strings.Join(typeNames, ", "))
+}
+
+// projectIssueTypeStatuses is the response of Jira's
+// "GET /rest/api/2/project/{projectKey}/statuses" endpoint.
+type projectIssueTypeStatuses struct {
+	Name     string `json:"name"`
+	Statuses []struct {
+		Name string `json:"name"`
+	} `json:"statuses"`
+}
+
+// WorkflowStatusMustExist checks that the status is part of the workflow
+// used by the issue type in the project. Issues can never reach a status
+// that exists in Jira but not in the workflow, which would otherwise only
+// surface as issues not being found when updating them.
+func (c *client) WorkflowStatusMustExist(projectKey, issueTypeName, statusName string) error {
+	req, err := c.raw.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/project/%s/statuses", projectKey), nil)
+	if err != nil {
+		return err
+	}
+	var issueTypes []projectIssueTypeStatuses
+	response, err := c.raw.Do(req, &issueTypes)
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving project statuses", response, err)
+	}
+	return workflowStatusMustExist(issueTypes, projectKey, issueTypeName, statusName)
+}
+
+func workflowStatusMustExist(issueTypes []projectIssueTypeStatuses, projectKey, issueTypeName, statusName string) error {
+	var typeNames []string
+	for _, issueType := range issueTypes {
+		if !strings.EqualFold(issueType.Name, issueTypeName) {
+			typeNames = append(typeNames, issueType.Name)
+			continue
+		}
+		var statusNames []string
+		for _, status := range issueType.Statuses {
+			if strings.EqualFold(status.Name, statusName) {
+				return nil
+			}
+			statusNames = append(statusNames, status.Name)
+		}
+		return fmt.Errorf("status %q is not part of the workflow for issue type %q in project %q, so issues cannot be created in it, but workflow has: %v",
+			statusName, issueTypeName, projectKey, strings.Join(statusNames, ", "))
+	}
+	return fmt.Errorf("issue type %q not found in project %q, but has: %v",
+		issueTypeName, projectKey, strings.Join(typeNames, ", "))
+}
+
+// projectWorkflowSchemes is the response of Jira's
+// "GET /rest/api/2/workflowscheme/project" endpoint.
+type projectWorkflowSchemes struct {
+	Values []struct {
+		WorkflowScheme struct {
+			DefaultWorkflow   string            `json:"defaultWorkflow"`
+			IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+		} `json:"workflowScheme"`
+	} `json:"values"`
+}
+
+// workflowSearch is the response of Jira's
+// "GET /rest/api/2/workflow/search" endpoint, expanded with the transitions
+// and statuses.
+type workflowSearch struct {
+	Values []workflowDefinition `json:"values"`
+}
+
+type workflowDefinition struct {
+	Transitions []struct {
+		Name string   `json:"name"`
+		Type string   `json:"type"`
+		From []string `json:"from"`
+		To   string   `json:"to"`
+	} `json:"transitions"`
+	Statuses []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"statuses"`
+}
+
+// CreatedIssueStatuses are the statuses of a workflow that created issues
+// start in, and can be transitioned to right after being created.
+type CreatedIssueStatuses struct {
+	Initial string
+	// Transitions are the names of the transitions available from the
+	// initial status, mapped to the names of their target statuses.
+	Transitions map[string]string
+}
+
+// Transitioned returns the status that created issues end up in when
+// transitioned to the status, which is looked up the same way as in
+// [Client.TransitionIssue], by target status or transition name.
+func (s CreatedIssueStatuses) Transitioned(statusName string) (string, bool) {
+	for _, target := range s.Transitions {
+		if strings.EqualFold(target, statusName) {
+			return target, true
+		}
+	}
+	for name, target := range s.Transitions {
+		if strings.EqualFold(name, statusName) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// WorkflowCreatedIssueStatuses returns the status that created issues of the
+// issue type start in, and the transitions available from it, according to
+// the project's workflow scheme. Requires the "Administer Jira" permission,
+// and is only supported by Jira Cloud.
+func (c *client) WorkflowCreatedIssueStatuses(projectKey, issueTypeName string) (CreatedIssueStatuses, error) {
+	workflow, workflowName, err := c.issueTypeWorkflow(projectKey, issueTypeName)
+	if err != nil {
+		return CreatedIssueStatuses{}, err
+	}
+	return createdIssueStatuses(workflow, workflowName)
+}
+
+func (c *client) issueTypeWorkflow(projectKey, issueTypeName string) (workflowDefinition, string, error) {
+	project, response, err := c.raw.Project.Get(projectKey)
+	if err != nil {
+		return workflowDefinition{}, "", c.responseError("error response from Jira when retrieving project", response, err)
+	}
+	var issueTypeID string
+	for _, issueType := range project.IssueTypes {
+		if strings.EqualFold(issueType.Name, issueTypeName) {
+			issueTypeID = issueType.ID
+		}
+	}
+	if issueTypeID == "" {
+		return workflowDefinition{}, "", fmt.Errorf("issue type %q not found in project %q", issueTypeName, projectKey)
+	}
+
+	req, err := c.raw.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/workflowscheme/project?projectId=%s", url.QueryEscape(project.ID)), nil)
+	if err != nil {
+		return workflowDefinition{}, "", err
+	}
+	var schemes projectWorkflowSchemes
+	response, err = c.raw.Do(req, &schemes)
+	if err != nil {
+		return workflowDefinition{}, "", c.responseError("error response from Jira when retrieving project workflow scheme", response, err)
+	}
+	if len(schemes.Values) == 0 {
+		return workflowDefinition{}, "", fmt.Errorf("no workflow scheme found for project %q", projectKey)
+	}
+	scheme := schemes.Values[0].WorkflowScheme
+	workflowName, ok := scheme.IssueTypeMappings[issueTypeID]
+	if !ok {
+		workflowName = scheme.DefaultWorkflow
+	}
+
+	req, err = c.raw.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/workflow/search?workflowName=%s&expand=transitions,statuses", url.QueryEscape(workflowName)), nil)
+	if err != nil {
+		return workflowDefinition{}, "", err
+	}
+	var workflows workflowSearch
+	response, err = c.raw.Do(req, &workflows)
+	if err != nil {
+		return workflowDefinition{}, "", c.responseError("error response from Jira when retrieving workflow", response, err)
+	}
+	if len(workflows.Values) == 0 {
+		return workflowDefinition{}, "", fmt.Errorf("workflow %q not found", workflowName)
+	}
+	return workflows.Values[0], workflowName, nil
+}
+
+func createdIssueStatuses(workflow workflowDefinition, workflowName string) (CreatedIssueStatuses, error) {
+	statusNames := map[string]string{}
+	for _, status := range workflow.Statuses {
+		statusNames[status.ID] = status.Name
+	}
+	var initialID string
+	for _, transition := range workflow.Transitions {
+		if transition.Type == "initial" {
+			initialID = transition.To
+			break
+		}
+	}
+	if initialID == "" {
+		return CreatedIssueStatuses{}, fmt.Errorf("no initial transition found in workflow %q", workflowName)
+	}
+	initial, ok := statusNames[initialID]
+	if !ok {
+		return CreatedIssueStatuses{}, fmt.Errorf("initial status with ID %q not found in workflow %q", initialID, workflowName)
+	}
+	statuses := CreatedIssueStatuses{Initial: initial, Transitions: map[string]string{}}
+	for _, transition := range workflow.Transitions {
+		// Global transitions have no "from" statuses, as they are
+		// available from every status.
+		fromInitial := len(transition.From) == 0 || slices.Contains(transition.From, initialID)
+		if transition.Type == "initial" || !fromInitial {
+			continue
+		}
+		if target, ok := statusNames[transition.To]; ok {
+			statuses.Transitions[transition.Name] = target
+		}
+	}
+	return statuses, nil
+}
+
+// myPermissions is the response of Jira's
+// "GET /rest/api/2/mypermissions" endpoint.
+type myPermissions struct {
+	Permissions map[string]struct {
+		Name           string `json:"name"`
+		HavePermission bool   `json:"havePermission"`
+	} `json:"permissions"`
+}
+
+// PermissionsMustExist checks that the configured Jira user has all of the
+// permissions in the project, such as "CREATE_ISSUES". Otherwise the missing
+// permissions would only surface as errors when handling the first webhook.
+func (c *client) PermissionsMustExist(projectKey string, permissions []string) error {
+	path := fmt.Sprintf("rest/api/2/mypermissions?projectKey=%s&permissions=%s",
+		url.QueryEscape(projectKey), url.QueryEscape(strings.Join(permissions, ",")))
+	req, err := c.raw.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	var got myPermissions
+	response, err := c.raw.Do(req, &got)
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving permissions", response, err)
+	}
+	return permissionsMustExist(got, projectKey, permissions)
+}
+
+func permissionsMustExist(got myPermissions, projectKey string, permissions []string) error {
+	var missing []string
+	for _, key := range permissions {
+		if !got.Permissions[key].HavePermission {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("configured Jira user lacks permissions in project %q: %s; ask a Jira admin to grant them via the project's permission scheme",
+			projectKey, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// projectSecurityLevels is the response of Jira's
+// "GET /rest/api/2/project/{projectKey}/securitylevel" endpoint, which only
+// lists the levels the configured Jira user is allowed to set.
+type projectSecurityLevels struct {
+	Levels []struct {
+		Name string `json:"name"`
+	} `json:"levels"`
+}
+
+// SecurityLevelMustExist checks that the issue security level can be set
+// on issues in the project.
+func (c *client) SecurityLevelMustExist(projectKey, levelName string) error {
+	req, err := c.raw.NewRequest(http.MethodGet, fmt.Sprintf("rest/api/2/project/%s/securitylevel", projectKey), nil)
+	if err != nil {
+		return err
+	}
+	var got projectSecurityLevels
+	response, err := c.raw.Do(req, &got)
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving project security levels", response, err)
+	}
+	return securityLevelMustExist(got, projectKey, levelName)
+}
+
+func securityLevelMustExist(got projectSecurityLevels, projectKey, levelName string) error {
+	var levelNames []string
+	for _, level := range got.Levels {
+		if strings.EqualFold(level.Name, levelName) {
+			return nil
+		}
+		levelNames = append(levelNames, level.Name)
+	}
+	if len(levelNames) == 0 {
+		return fmt.Errorf("security level %q not found in project %q, as the project has no issue security scheme or the configured Jira user lacks the \"Set Issue Security\" permission",
+			levelName, projectKey)
+	}
+	return fmt.Errorf("security level %q not found in project %q, but has: %v",
+		levelName, projectKey, strings.Join(levelNames, ", "))
+}
+
+func (c *client) LinkTypeMustExist(linkType string) error {
+	linkTypes, response, err := c.raw.IssueLinkType.GetList()
+	if err != nil {
+		return c.responseError("error response from Jira when retrieving issue link types", response, err)
+	}
+	return linkTypeMustExist(linkTypes, linkType)
+}
+
+func linkTypeMustExist(linkTypes []jira.IssueLinkType, linkType string) error {
+	var names []string
+	for _, t := range linkTypes {
+		if t.Name == linkType {
+			return nil
+		}
+		names = append(names, t.Name)
+	}
+	return fmt.Errorf("issue link type %q not found in Jira, but has: %v",
+		linkType, strings.Join(names, ", "))
+}
+
+func (c *client) UserMustExist(user string) error {
+	users, response, err := c.raw.User.Find(user, jira.WithUsername(user))
+	if err != nil {
+		return c.responseError("error response from Jira when searching for user", response, err)
+	}
+	for _, u := range users {
+		if u.Name == user || u.Key == user || u.AccountID == user {
+			return nil
+		}
+	}
+	return fmt.Errorf("user %q not found", user)
+}
+
+// userRef creates a reference to a Jira user. Jira Cloud identifies users by
+// their account ID, while Jira Server and Data Center uses usernames.
+func (c *client) userRef(user string) *jira.User {
+	if c.cfg.Auth.Type == config.JiraAuthTypeToken {
+		return &jira.User{AccountID: user}
+	}
+	return &jira.User{Name: user}
+}
+
+// browseURL returns the URL to view the issue in the Jira web UI.
+func (c *client) browseURL(issueKey string) string {
+	return strings.TrimSuffix(c.cfg.URL, "/") + "/browse/" + issueKey
+}
+
+// FindIssuesForPackage searches for existing issues for a package in the Jira
+// project, as labels are not scoped to a project. If a match label is given,
+// then only issues with that label are returned.
+func (c *client) FindIssuesForPackage(ctx context.Context, projectKey, packageName, matchLabel string) ([]Issue, error) {
+	statusNames := c.cfg.Issue.StatusesToSearch()
+	if c.cfg.Issue.ReopenClosed {
+		// Search issues in all statuses, so closed issues can be reopened
+		statusNames = nil
+	}
+	var requiredLabels []string
+	for _, label := range []string{c.cfg.Issue.ManagedLabel, matchLabel} {
+		if label != "" {
+			requiredLabels = append(requiredLabels, label)
+		}
+	}
+	query := newJiraIssueSearchQuery(projectKey, statusNames, requiredLabels, packageName, c.cfg.Issue.ProjectNameCustomField)
+	found, err := c.FindIssuesWithJQL(ctx, query)
 	if err != nil {
-		err := fmt.Errorf("searching Jira for previous issues: %w", err)
-		logJiraErrResponse(resp, err)
 		return nil, err
 	}
-	issues := make([]Issue, 0, len(rawIssues))
-	for _, rawIssue := range rawIssues {
-		iss := newIssue(rawIssue, c.cfg.Issue.ProjectNameCustomField)
+	issues := make([]Issue, 0, len(found))
+	for _, iss := range found {
 		if iss.PackageName != packageName {
 			log.Debug().
 				Str("package", packageName).
@@ -236,73 +817,296 @@ func (c *client) FindIssuesForPackage(packageName string) ([]Issue, error) {
 	return issues, nil
 }
 
-func newJiraIssueSearchQuery(statusName, projectName string, customFieldID uint) string {
+// FindIssuesWithJQL searches for existing issues using a custom JQL query.
+func (c *client) FindIssuesWithJQL(ctx context.Context, jql string) ([]Issue, error) {
+	rawIssues, resp, err := searchAllPages(int(c.cfg.MaxSearchResults), func(startAt, maxResults int) (issues []jira.Issue, resp *jira.Response, err error) {
+		resp, err = c.doWithRetry(ctx, "search", func(ctx context.Context) (resp *jira.Response, err error) {
+			issues, resp, err = c.raw.Issue.SearchWithContext(ctx, jql, &jira.SearchOptions{
+				StartAt:    startAt,
+				MaxResults: maxResults,
+			})
+			return resp, err
+		})
+		return issues, resp, err
+	})
+	if err != nil {
+		err := fmt.Errorf("searching Jira for previous issues: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(rawIssues))
+	for _, rawIssue := range rawIssues {
+		issues = append(issues, newIssue(rawIssue, c.cfg.Issue.ProjectNameCustomField))
+	}
+	return issues, nil
+}
+
+// FindIssueWithLabel returns the most recently created issue that has the
+// given label, in any status.
+func (c *client) FindIssueWithLabel(ctx context.Context, label string) (Issue, bool, error) {
+	query := fmt.Sprintf("labels = %s ORDER BY created DESC", QuoteJQL(label))
+	var rawIssues []jira.Issue
+	resp, err := c.doWithRetry(ctx, "search", func(ctx context.Context) (resp *jira.Response, err error) {
+		rawIssues, resp, err = c.raw.Issue.SearchWithContext(ctx, query, &jira.SearchOptions{
+			MaxResults: 1,
+		})
+		return resp, err
+	})
+	if err != nil {
+		err := fmt.Errorf("searching Jira for issues with label %q: %w", label, err)
+		c.logJiraErrResponse(resp, err)
+		return Issue{}, false, err
+	}
+	if len(rawIssues) == 0 {
+		return Issue{}, false, nil
+	}
+	return newIssue(rawIssues[0], c.cfg.Issue.ProjectNameCustomField), true, nil
+}
+
+// searchPageSize is the number of issues to request per search page.
+// Jira may cap it to a lower value, which is handled by searchAllPages.
+const searchPageSize = 100
+
+// searchAllPages calls the search function for each page of results,
+// until all results are retrieved or the limit is reached.
+// A limit of 0 means no limit.
+func searchAllPages(limit int, search func(startAt, maxResults int) ([]jira.Issue, *jira.Response, error)) ([]jira.Issue, *jira.Response, error) {
+	var all []jira.Issue
+	for {
+		pageSize := searchPageSize
+		if limit > 0 && limit-len(all) < pageSize {
+			pageSize = limit - len(all)
+		}
+		page, resp, err := search(len(all), pageSize)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, page...)
+		if len(page) == 0 || resp == nil || len(all) >= resp.Total {
+			return all, resp, nil
+		}
+		if limit > 0 && len(all) >= limit {
+			log.Warn().
+				Int("limit", limit).
+				Int("total", resp.Total).
+				Msg("Too many Jira search results. Ignoring the rest.")
+			return all, resp, nil
+		}
+	}
+}
+
+// newJiraIssueSearchQuery creates a JQL query for finding issues for a
+// package. The Jira project and status are not filtered on if no project key
+// or status names are given. Only issues that have all of the required labels
+// are matched.
+func newJiraIssueSearchQuery(projectKey string, statusNames []string, requiredLabels []string, projectName string, customFieldID uint) string {
+	var filterClause string
+	if projectKey != "" {
+		filterClause = fmt.Sprintf("project = %s and ", QuoteJQL(projectKey))
+	}
+	switch len(statusNames) {
+	case 0:
+	case 1:
+		filterClause += fmt.Sprintf("status = %s and ", QuoteJQL(statusNames[0]))
+	default:
+		quoted := make([]string, len(statusNames))
+		for i, name := range statusNames {
+			quoted[i] = QuoteJQL(name)
+		}
+		filterClause += fmt.Sprintf("status in (%s) and ", strings.Join(quoted, ", "))
+	}
+	for _, label := range requiredLabels {
+		filterClause += fmt.Sprintf("labels = %s and ", QuoteJQL(label))
+	}
 	if customFieldID == 0 {
-		return fmt.Sprintf("status = %q and labels = %q ORDER BY created DESC", statusName, projectName)
+		return fmt.Sprintf("%slabels = %s ORDER BY created DESC", filterClause, QuoteJQL(projectName))
 	}
 	// Checking label as well for backward compatibility
-	return fmt.Sprintf("status = %q and (labels = %q or cf[%d] ~ %[2]q) ORDER BY created DESC",
-		statusName, projectName, customFieldID)
+	return fmt.Sprintf("%s(labels = %s or cf[%d] ~ %[2]s) ORDER BY created DESC",
+		filterClause, QuoteJQL(projectName), customFieldID)
+}
+
+var jqlEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// QuoteJQL returns a double-quoted JQL string literal, with special
+// characters escaped so the value cannot break out of the string.
+func QuoteJQL(s string) string {
+	return `"` + jqlEscaper.Replace(s) + `"`
 }
 
-func logJiraErrResponse(resp *jira.Response, err error) {
+func observeRequest(operation string, resp *jira.Response, start time.Time) {
+	var statusCode int
 	if resp != nil {
-		body, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			log.Error().Err(err).Msg("Failed Jira request, and to decode Jira response body.")
-			return
-		}
-		var obj any
-		if err := json.Unmarshal(body, &obj); err != nil {
-			log.Error().Err(err).Str("body", string(body)).Msg("Failed Jira request.")
-			return
-		}
-		log.Error().Err(err).Interface("body", obj).Msg("Failed Jira request.")
-		return
+		statusCode = resp.StatusCode
 	}
-	log.Error().Err(err).Msg("Failed to create Jira issue.")
+	metrics.ObserveJiraRequest(operation, statusCode, start)
 }
 
-func (c *client) UpdateIssueSummary(issueRef IssueRef, newSummary string) error {
-	// This seems hacky, but is taken from the official examples
-	// https://github.com/andygrunwald/go-jira/blob/47d27a76e84da43f6e27e1cd0f930e6763dc79d7/examples/addlabel/main.go
-	// There is also a jiraClient.Issue.Update() method, but it panics and does not provide a usage example
-	type summaryUpdate struct {
-		Set string `json:"set" structs:"set"`
+// hasFieldErrorResponse checks if Jira rejected the request because of an
+// error on the field, such as "reporter" when Jira requires it to be set.
+// The response body is left intact for later use.
+func hasFieldErrorResponse(resp *jira.Response, field string) bool {
+	if resp == nil || resp.Body == nil {
+		return false
 	}
-	type issueUpdate struct {
-		Summary []summaryUpdate `json:"summary" structs:"summary"`
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
 	}
-	updates := map[string]any{
-		"update": issueUpdate{
-			Summary: []summaryUpdate{
-				{Set: newSummary},
-			},
-		},
+	var errResp struct {
+		Errors map[string]string `json:"errors"`
 	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+	_, ok := errResp.Errors[field]
+	return ok
+}
+
+func (c *client) UpdateIssueSummary(ctx context.Context, issueRef IssueRef, newSummary string) error {
+	updates := newSetSummaryUpdate(newSummary)
 	log.Trace().Interface("updates", updates).Msg("Updating issue.")
-	resp, err := c.raw.Issue.UpdateIssue(issueRef.ID, updates)
+	resp, err := c.doWithRetry(ctx, "update", func(ctx context.Context) (*jira.Response, error) {
+		return c.raw.Issue.UpdateIssueWithContext(ctx, issueRef.ID, updates)
+	})
 	if err != nil {
 		err := fmt.Errorf("update Jira issue: %w", err)
-		logJiraErrResponse(resp, err)
+		c.logJiraErrResponse(resp, err)
 		return err
 	}
 	log.Info().
 		Str("issue", issueRef.Key).
+		Str("url", c.browseURL(issueRef.Key)).
 		Str("summary", newSummary).
 		Msg("Updated issue summary")
 	return nil
 }
 
-func (c *client) CreateIssue(issue Issue) (IssueRef, error) {
+// AddIssueLabels adds labels to an existing issue, while keeping any other
+// labels already on the issue, such as labels added manually by humans.
+func (c *client) AddIssueLabels(ctx context.Context, issueRef IssueRef, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	updates := newAddLabelsUpdate(labels)
+	log.Trace().Interface("updates", updates).Msg("Updating issue.")
+	resp, err := c.doWithRetry(ctx, "update", func(ctx context.Context) (*jira.Response, error) {
+		return c.raw.Issue.UpdateIssueWithContext(ctx, issueRef.ID, updates)
+	})
+	if err != nil {
+		err := fmt.Errorf("add labels to Jira issue: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return err
+	}
+	log.Info().
+		Str("issue", issueRef.Key).
+		Strs("labels", labels).
+		Msg("Added labels to issue.")
+	return nil
+}
+
+// AddIssueWatcher adds a user as watcher on the issue. The user is the
+// username on Jira Server/Data Center, and the account ID on Jira Cloud.
+func (c *client) AddIssueWatcher(ctx context.Context, issueRef IssueRef, user string) error {
+	resp, err := c.doWithRetry(ctx, "watcher", func(ctx context.Context) (*jira.Response, error) {
+		return c.raw.Issue.AddWatcherWithContext(ctx, issueRef.ID, user)
+	})
+	if err != nil {
+		err := fmt.Errorf("add watcher to Jira issue: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return err
+	}
+	log.Info().
+		Str("issue", issueRef.Key).
+		Str("watcher", user).
+		Msg("Added watcher to issue.")
+	return nil
+}
+
+// LinkIssues links the two issues using the issue link type, such as
+// "Relates" or "Blocks".
+func (c *client) LinkIssues(ctx context.Context, linkType string, inward, outward IssueRef) error {
+	link := &jira.IssueLink{
+		Type:         jira.IssueLinkType{Name: linkType},
+		InwardIssue:  &jira.Issue{Key: inward.Key},
+		OutwardIssue: &jira.Issue{Key: outward.Key},
+	}
+	resp, err := c.doWithRetry(ctx, "link", func(ctx context.Context) (*jira.Response, error) {
+		return c.raw.Issue.AddLinkWithContext(ctx, link)
+	})
+	if err != nil {
+		err := fmt.Errorf("link Jira issues: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return err
+	}
+	log.Info().
+		Str("inward", inward.Key).
+		Str("outward", outward.Key).
+		Str("linkType", linkType).
+		Msg("Linked issues.")
+	return nil
+}
+
+func (c *client) CreateIssue(ctx context.Context, issue Issue) (IssueRef, error) {
 	req := issue.rawIssue()
-	created, resp, err := c.raw.Issue.Create(&req)
+	if issue.Assignee != "" {
+		req.Fields.Assignee = c.userRef(issue.Assignee)
+	}
+	if issue.Reporter != "" {
+		req.Fields.Reporter = c.userRef(issue.Reporter)
+	}
+	if issue.FixVersion != "" {
+		found, err := c.ensureVersion(ctx, issue.ProjectKey, issue.FixVersion)
+		switch {
+		case err != nil:
+			log.Warn().Err(err).
+				Str("version", issue.FixVersion).
+				Msg("Failed to look up fix version. Creating issue without it.")
+		case !found:
+			log.Warn().
+				Str("project", issue.ProjectKey).
+				Str("version", issue.FixVersion).
+				Msg("Fix version not found in Jira project. Creating issue without it.")
+		default:
+			req.Fields.FixVersions = []*jira.FixVersion{{Name: issue.FixVersion}}
+		}
+	}
+	if c.cfg.APIVersion == config.JiraAPIVersionV3 {
+		setADFDescription(&req)
+	}
+	var created *jira.Issue
+	resp, err := c.doWithRetry(ctx, "create", func(ctx context.Context) (resp *jira.Response, err error) {
+		created, resp, err = c.createRawIssue(ctx, &req)
+		return resp, err
+	})
 	if err != nil {
 		err := fmt.Errorf("creating Jira issue: %w", err)
-		logJiraErrResponse(resp, err)
+		switch {
+		case issue.Reporter == "" && hasFieldErrorResponse(resp, "reporter"):
+			err = fmt.Errorf("%w; Jira requires the reporter to be set, which can be configured via the jira.issue.reporter config field", err)
+		case issue.SecurityLevel == "" && hasFieldErrorResponse(resp, "security"):
+			err = fmt.Errorf("%w; Jira requires the security level to be set, which can be configured via the jira.issue.securityLevel config field", err)
+		case issue.SecurityLevel != "" && hasFieldErrorResponse(resp, "security"):
+			err = fmt.Errorf("%w; Jira rejected the security level %q configured via the jira.issue.securityLevel config field", err, issue.SecurityLevel)
+		}
+		c.logJiraErrResponse(resp, err)
 		return IssueRef{}, err
 	}
-	log.Info().Str("issue", created.Key).Msg("Created issue.")
+	if created == nil {
+		return IssueRef{}, errors.New("creating Jira issue: got empty response")
+	}
+	log.Info().
+		Str("issue", created.Key).
+		Str("url", c.browseURL(created.Key)).
+		Msg("Created issue.")
 	// NOTE: Jira's "create issue" endpoint only contains the ID and Key fields
 	return IssueRef{
 		ID:  created.ID,
@@ -310,15 +1114,175 @@ func (c *client) CreateIssue(issue Issue) (IssueRef, error) {
 	}, nil
 }
 
-func (c *client) CreateIssueComment(issueRef IssueRef, newComment string) error {
-	_, resp, err := c.raw.Issue.AddComment(issueRef.ID, &jira.Comment{
+// createRawIssue creates the issue using the configured Jira REST API version.
+func (c *client) createRawIssue(ctx context.Context, issue *jira.Issue) (*jira.Issue, *jira.Response, error) {
+	if c.cfg.APIVersion != config.JiraAPIVersionV3 {
+		return c.raw.Issue.CreateWithContext(ctx, issue)
+	}
+	req, err := c.raw.NewRequestWithContext(ctx, http.MethodPost, "rest/api/3/issue", issue)
+	if err != nil {
+		return nil, nil, err
+	}
+	created := new(jira.Issue)
+	resp, err := c.raw.Do(req, created)
+	if err != nil {
+		return nil, resp, err
+	}
+	return created, resp, nil
+}
+
+// setADFDescription replaces the plain text description with an Atlassian
+// Document Format (ADF) document, as required by the REST API v3.
+func setADFDescription(issue *jira.Issue) {
+	if issue.Fields.Description == "" {
+		return
+	}
+	if issue.Fields.Unknowns == nil {
+		issue.Fields.Unknowns = tcontainer.MarshalMap{}
+	}
+	issue.Fields.Unknowns["description"] = adfDocument(issue.Fields.Description)
+	issue.Fields.Description = ""
+}
+
+// ensureVersion checks if the version exists in the Jira project, and
+// creates it if it's missing and Config.Jira.Issue.CreateMissingVersions
+// is enabled. Returns false if the version does not exist afterwards.
+func (c *client) ensureVersion(ctx context.Context, projectKey, versionName string) (bool, error) {
+	project, resp, err := c.raw.Project.GetWithContext(ctx, projectKey)
+	if err != nil {
+		err := fmt.Errorf("get Jira project: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return false, err
+	}
+	for _, v := range project.Versions {
+		if v.Name == versionName {
+			return true, nil
+		}
+	}
+	if !c.cfg.Issue.CreateMissingVersions {
+		return false, nil
+	}
+	projectID, err := strconv.Atoi(project.ID)
+	if err != nil {
+		return false, fmt.Errorf("parse Jira project ID: %w", err)
+	}
+	created, resp, err := c.raw.Version.CreateWithContext(ctx, &jira.Version{
+		Name:      versionName,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		err := fmt.Errorf("create Jira version: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return false, err
+	}
+	// distinct event, as this is a side effect in Jira beyond the issues,
+	// which operators may need to audit
+	log.Info().
+		Str("event", "jiraVersionCreated").
+		Str("project", projectKey).
+		Str("version", created.Name).
+		Str("versionId", created.ID).
+		Msg("Created version in Jira project.")
+	metrics.IncVersionCreated(projectKey)
+	return true, nil
+}
+
+// jiraTimeLayout is the layout of timestamps in Jira's REST API responses.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// GetIssueComments returns all comments on the issue, oldest first.
+func (c *client) GetIssueComments(ctx context.Context, issueRef IssueRef) ([]Comment, error) {
+	var issue *jira.Issue
+	resp, err := c.doWithRetry(ctx, "comments", func(ctx context.Context) (resp *jira.Response, err error) {
+		issue, resp, err = c.raw.Issue.GetWithContext(ctx, issueRef.ID, &jira.GetQueryOptions{Fields: "comment"})
+		return resp, err
+	})
+	if err != nil {
+		err := fmt.Errorf("get Jira issue comments: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return nil, err
+	}
+	if issue == nil || issue.Fields == nil || issue.Fields.Comments == nil {
+		return nil, nil
+	}
+	return newComments(issue.Fields.Comments.Comments), nil
+}
+
+func newComments(rawComments []*jira.Comment) []Comment {
+	comments := make([]Comment, 0, len(rawComments))
+	for _, raw := range rawComments {
+		if raw == nil {
+			continue
+		}
+		// zero if unparseable, as the timestamp is only informative
+		created, _ := time.Parse(jiraTimeLayout, raw.Created)
+		comments = append(comments, Comment{
+			Author:     raw.Author.DisplayName,
+			Created:    created,
+			Body:       raw.Body,
+			Restricted: raw.Visibility.Type != "" || raw.Visibility.Value != "",
+		})
+	}
+	return comments
+}
+
+func (c *client) CreateIssueComment(ctx context.Context, issueRef IssueRef, newComment string) error {
+	start := time.Now()
+	_, resp, err := c.raw.Issue.AddCommentWithContext(ctx, issueRef.ID, &jira.Comment{
 		Body: newComment,
 	})
+	observeRequest("comment", resp, start)
 	if err != nil {
 		err := fmt.Errorf("creating Jira comment: %w", err)
-		logJiraErrResponse(resp, err)
+		c.logJiraErrResponse(resp, err)
 		return err
 	}
 	log.Info().Str("issue", issueRef.Key).Msg("Created comment on issue.")
 	return nil
 }
+
+func (c *client) TransitionIssue(ctx context.Context, issueRef IssueRef, statusName string) error {
+	transitions, resp, err := c.raw.Issue.GetTransitionsWithContext(ctx, issueRef.ID)
+	if err != nil {
+		err := fmt.Errorf("get Jira issue transitions: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return err
+	}
+	transition, ok := findTransition(transitions, statusName)
+	if !ok {
+		var names []string
+		for _, t := range transitions {
+			names = append(names, t.To.Name)
+		}
+		return fmt.Errorf("no transition to status %q found for issue %s, but has: %v",
+			statusName, issueRef.Key, strings.Join(names, ", "))
+	}
+	resp, err = c.raw.Issue.DoTransitionWithContext(ctx, issueRef.ID, transition.ID)
+	if err != nil {
+		err := fmt.Errorf("transition Jira issue: %w", err)
+		c.logJiraErrResponse(resp, err)
+		return err
+	}
+	log.Info().
+		Str("issue", issueRef.Key).
+		Str("status", statusName).
+		Msg("Transitioned issue.")
+	return nil
+}
+
+// findTransition looks up a transition by its target status name, or by
+// the name of the transition itself. Jira transitions are referenced by ID
+// in the API, while the names are what users see in the Jira UI.
+func findTransition(transitions []jira.Transition, statusName string) (jira.Transition, bool) {
+	for _, t := range transitions {
+		if strings.EqualFold(t.To.Name, statusName) {
+			return t, true
+		}
+	}
+	for _, t := range transitions {
+		if strings.EqualFold(t.Name, statusName) {
+			return t, true
+		}
+	}
+	return jira.Transition{}, false
+}
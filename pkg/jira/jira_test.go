@@ -17,38 +17,694 @@
 
 package jira
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/andygrunwald/go-jira"
+	"github.com/trivago/tgo/tcontainer"
+)
 
 func TestNewJiraIssueSearchQuery(t *testing.T) {
 	tests := []struct {
-		name        string
-		status      string
-		project     string
-		customField uint
-		want        string
+		name           string
+		projectKey     string
+		status         []string
+		requiredLabels []string
+		project        string
+		customField    uint
+		want           string
 	}{
 		{
 			name:        "no custom field",
-			status:      "Grooming",
+			status:      []string{"Grooming"},
 			project:     "platform/jelease",
 			customField: 0,
 			want:        `status = "Grooming" and labels = "platform/jelease" ORDER BY created DESC`,
 		},
 		{
 			name:        "with custom field",
-			status:      "Grooming",
+			status:      []string{"Grooming"},
 			project:     "platform/jelease",
 			customField: 12500,
 			want:        `status = "Grooming" and (labels = "platform/jelease" or cf[12500] ~ "platform/jelease") ORDER BY created DESC`,
 		},
+		{
+			name:        "no status",
+			project:     "platform/jelease",
+			customField: 0,
+			want:        `labels = "platform/jelease" ORDER BY created DESC`,
+		},
+		{
+			name:        "multiple statuses",
+			status:      []string{"Open", "In Progress", `Say "hi"`},
+			project:     "platform/jelease",
+			customField: 0,
+			want:        `status in ("Open", "In Progress", "Say \"hi\"") and labels = "platform/jelease" ORDER BY created DESC`,
+		},
+		{
+			name:        "with project key",
+			projectKey:  "OP",
+			status:      []string{"Grooming"},
+			project:     "platform/jelease",
+			customField: 0,
+			want:        `project = "OP" and status = "Grooming" and labels = "platform/jelease" ORDER BY created DESC`,
+		},
+		{
+			name:           "with required labels",
+			status:         []string{"Grooming"},
+			requiredLabels: []string{"jelease", "platform/jelease@1.2"},
+			project:        "platform/jelease",
+			customField:    12500,
+			want:           `status = "Grooming" and labels = "jelease" and labels = "platform/jelease@1.2" and (labels = "platform/jelease" or cf[12500] ~ "platform/jelease") ORDER BY created DESC`,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := newJiraIssueSearchQuery(tc.status, tc.project, tc.customField)
+			got := newJiraIssueSearchQuery(tc.projectKey, tc.status, tc.requiredLabels, tc.project, tc.customField)
 			if tc.want != got {
 				t.Errorf("Wrong query.\nwant: `%s`\ngot:  `%s`", tc.want, got)
 			}
 		})
 	}
 }
+
+func TestFindTransition(t *testing.T) {
+	transitions := []jira.Transition{
+		{ID: "11", Name: "Start progress", To: jira.Status{Name: "In Progress"}},
+		{ID: "21", Name: "Reopen", To: jira.Status{Name: "Backlog"}},
+		{ID: "31", Name: "Close", To: jira.Status{Name: "Done"}},
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		wantID string
+	}{
+		{name: "by target status", status: "Backlog", wantID: "21"},
+		{name: "case insensitive", status: "in progress", wantID: "11"},
+		{name: "by transition name", status: "Reopen", wantID: "21"},
+		{name: "not found", status: "Grooming", wantID: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := findTransition(transitions, tc.status)
+			if tc.wantID == "" {
+				if ok {
+					t.Errorf("want not found, got %q", got.ID)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("want %q, got not found", tc.wantID)
+			}
+			if got.ID != tc.wantID {
+				t.Errorf("want %q, got %q", tc.wantID, got.ID)
+			}
+		})
+	}
+}
+
+func TestSearchAllPages(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		maxPageSize int // Jira caps the page size
+		limit       int
+		want        int
+		wantCalls   int
+	}{
+		{name: "no results", total: 0, maxPageSize: 50, want: 0, wantCalls: 1},
+		{name: "single page", total: 20, maxPageSize: 50, want: 20, wantCalls: 1},
+		{name: "multiple pages", total: 120, maxPageSize: 50, want: 120, wantCalls: 3},
+		{name: "limited", total: 120, maxPageSize: 50, limit: 70, want: 70, wantCalls: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			got, _, err := searchAllPages(tc.limit, func(startAt, maxResults int) ([]jira.Issue, *jira.Response, error) {
+				calls++
+				if maxResults > tc.maxPageSize {
+					maxResults = tc.maxPageSize
+				}
+				var page []jira.Issue
+				for i := startAt; i < tc.total && len(page) < maxResults; i++ {
+					page = append(page, jira.Issue{Key: fmt.Sprintf("OP-%d", i)})
+				}
+				return page, &jira.Response{StartAt: startAt, MaxResults: maxResults, Total: tc.total}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tc.want {
+				t.Errorf("want %d issues, got %d", tc.want, len(got))
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("want %d calls, got %d", tc.wantCalls, calls)
+			}
+			for i, issue := range got {
+				if want := fmt.Sprintf("OP-%d", i); issue.Key != want {
+					t.Fatalf("index %d: want %q, got %q", i, want, issue.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestJQLQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "platform/jelease", want: `"platform/jelease"`},
+		{name: "double quotes", value: `foo" or labels != "bar`, want: `"foo\" or labels != \"bar"`},
+		{name: "backslashes", value: `foo\bar\`, want: `"foo\\bar\\"`},
+		{name: "backslash before quote", value: `foo\"`, want: `"foo\\\""`},
+		{name: "leading digits", value: "7zip", want: `"7zip"`},
+		{name: "newline", value: "foo\nbar", want: `"foo\nbar"`},
+		{name: "unicode", value: "føø", want: `"føø"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := QuoteJQL(tc.value)
+			if tc.want != got {
+				t.Errorf("want `%s`, got `%s`", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHasFieldErrorResponse(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		want  bool
+	}{
+		{name: "reporter", body: `{"errorMessages":[],"errors":{"reporter":"Reporter is required."}}`, field: "reporter", want: true},
+		{name: "security", body: `{"errorMessages":[],"errors":{"security":"Security Level is required."}}`, field: "security", want: true},
+		{name: "other", body: `{"errorMessages":[],"errors":{"summary":"Summary is required."}}`, field: "reporter", want: false},
+		{name: "html", body: `<html>Login</html>`, field: "reporter", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &jira.Response{Response: &http.Response{
+				Body: io.NopCloser(strings.NewReader(tc.body)),
+			}}
+			if got := hasFieldErrorResponse(resp, tc.field); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tc.body {
+				t.Errorf("want body to be left intact, got %q", body)
+			}
+		})
+	}
+}
+
+func TestSummarizeResponse(t *testing.T) {
+	c := &client{cfg: &config.Jira{MaxErrorBodyLength: 10}}
+	body := "<html><body>Please log in</body></html>"
+	resp := &jira.Response{Response: &http.Response{
+		StatusCode: 401,
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}}
+	got := c.summarizeResponse(resp)
+	want := `status 401, content type "text/html; charset=utf-8", body: <html><bod... (truncated 29 bytes)`
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	rest, _ := io.ReadAll(resp.Body)
+	if string(rest) != body {
+		t.Errorf("want body to be left intact, got %q", rest)
+	}
+}
+
+func TestSetADFDescription(t *testing.T) {
+	issue := Issue{
+		Summary:     "Update neuvector to version 5.1.3",
+		Description: "Acceptance criteria:\n\n* Checked changelogs\n* Updated",
+	}.rawIssue()
+	setADFDescription(&issue)
+
+	b, err := json.Marshal(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Fields struct {
+			Description any `json:"description"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"version": 1.0,
+		"type":    "doc",
+		"content": []any{
+			map[string]any{
+				"type": "paragraph",
+				"content": []any{
+					map[string]any{"type": "text", "text": "Acceptance criteria:"},
+				},
+			},
+			map[string]any{
+				"type": "paragraph",
+				"content": []any{
+					map[string]any{"type": "text", "text": "* Checked changelogs"},
+					map[string]any{"type": "hardBreak"},
+					map[string]any{"type": "text", "text": "* Updated"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(want, got.Fields.Description) {
+		t.Errorf("want %v, got %v", want, got.Fields.Description)
+	}
+}
+
+func TestRawIssue_customFields(t *testing.T) {
+	issue := Issue{
+		PackageName:        "neuvector",
+		PackageNameFieldID: 1084,
+		CustomFields: map[string]any{
+			"customfield_10050": "Team A",
+			"customfield_10051": []any{"backend", "ops"},
+		},
+	}.rawIssue()
+
+	want := tcontainer.MarshalMap{
+		"customfield_1084":  "neuvector",
+		"customfield_10050": "Team A",
+		"customfield_10051": []any{"backend", "ops"},
+	}
+	if !reflect.DeepEqual(issue.Fields.Unknowns, want) {
+		t.Errorf("want %v, got %v", want, issue.Fields.Unknowns)
+	}
+}
+
+func TestRawIssue_dueDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dueDate time.Time
+		want    any
+	}{
+		{name: "unset", want: nil},
+		{name: "set", dueDate: time.Date(2023, 3, 17, 15, 4, 5, 0, time.UTC), want: "2023-03-17"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(Issue{DueDate: tc.dueDate}.rawIssue())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Fields map[string]any `json:"fields"`
+			}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Fields["duedate"] != tc.want {
+				t.Errorf("want duedate %v, got %v", tc.want, got.Fields["duedate"])
+			}
+		})
+	}
+}
+
+func TestRawIssue_securityLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		securityLevel string
+		want          any
+	}{
+		{name: "unset", want: nil},
+		{name: "set", securityLevel: "Internal", want: map[string]any{"name": "Internal"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(Issue{SecurityLevel: tc.securityLevel}.rawIssue())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Fields map[string]any `json:"fields"`
+			}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Fields["security"], tc.want) {
+				t.Errorf("want security %v, got %v", tc.want, got.Fields["security"])
+			}
+		})
+	}
+}
+
+func TestRawIssue_parent(t *testing.T) {
+	issue := Issue{ParentKey: "OP-123"}.rawIssue()
+	if issue.Fields.Parent == nil || issue.Fields.Parent.Key != "OP-123" {
+		t.Errorf("want parent %q, got %+v", "OP-123", issue.Fields.Parent)
+	}
+	if issue := (Issue{}).rawIssue(); issue.Fields.Parent != nil {
+		t.Errorf("want no parent, got %+v", issue.Fields.Parent)
+	}
+}
+
+func TestWorkflowStatusMustExist(t *testing.T) {
+	var issueTypes []projectIssueTypeStatuses
+	err := json.Unmarshal([]byte(`[
+		{"name": "Task", "statuses": [{"name": "To Do"}, {"name": "Done"}]},
+		{"name": "Bug", "statuses": [{"name": "Open"}, {"name": "Closed"}]}
+	]`), &issueTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		issueType string
+		status    string
+		wantErr   bool
+	}{
+		{name: "found", issueType: "Task", status: "To Do"},
+		{name: "case insensitive", issueType: "task", status: "done"},
+		{name: "other workflow", issueType: "Task", status: "Open", wantErr: true},
+		{name: "missing issue type", issueType: "Epic", status: "To Do", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := workflowStatusMustExist(issueTypes, "OP", tc.issueType, tc.status)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreatedIssueStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     CreatedIssueStatuses
+		wantErr  bool
+	}{
+		{
+			name: "initial",
+			workflow: `{
+				"transitions": [
+					{"name": "Start", "type": "global", "from": [], "to": "3"},
+					{"name": "Create", "type": "initial", "from": [], "to": "1"},
+					{"name": "Resolve", "type": "directed", "from": ["1"], "to": "4"},
+					{"name": "Reopen", "type": "directed", "from": ["4"], "to": "1"}
+				],
+				"statuses": [{"id": "3", "name": "In Progress"}, {"id": "1", "name": "Open"}, {"id": "4", "name": "Done"}]
+			}`,
+			want: CreatedIssueStatuses{
+				Initial:     "Open",
+				Transitions: map[string]string{"Start": "In Progress", "Resolve": "Done"},
+			},
+		},
+		{
+			name:     "missing status",
+			workflow: `{"transitions": [{"type": "initial", "to": "1"}], "statuses": [{"id": "3", "name": "In Progress"}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing transition",
+			workflow: `{"transitions": [{"type": "directed", "to": "3"}], "statuses": [{"id": "3", "name": "In Progress"}]}`,
+			wantErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var workflow workflowDefinition
+			if err := json.Unmarshal([]byte(tc.workflow), &workflow); err != nil {
+				t.Fatal(err)
+			}
+			got, err := createdIssueStatuses(workflow, "Software Simplified Workflow")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCreatedIssueStatusesTransitioned(t *testing.T) {
+	statuses := CreatedIssueStatuses{
+		Initial:     "Open",
+		Transitions: map[string]string{"Start": "In Progress"},
+	}
+	tests := []struct {
+		name   string
+		status string
+		want   string
+		wantOK bool
+	}{
+		{name: "target status", status: "in progress", want: "In Progress", wantOK: true},
+		{name: "transition name", status: "start", want: "In Progress", wantOK: true},
+		{name: "missing", status: "Done"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := statuses.Transitioned(tc.status)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("want %q, %t, got %q, %t", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestPermissionsMustExist(t *testing.T) {
+	var got myPermissions
+	err := json.Unmarshal([]byte(`{"permissions": {
+		"CREATE_ISSUES": {"name": "Create Issues", "havePermission": true},
+		"EDIT_ISSUES": {"name": "Edit Issues", "havePermission": false}
+	}}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		permissions []string
+		wantErr     string
+	}{
+		{name: "granted", permissions: []string{"CREATE_ISSUES"}},
+		{name: "missing", permissions: []string{"CREATE_ISSUES", "EDIT_ISSUES"}, wantErr: "EDIT_ISSUES"},
+		{name: "not in response", permissions: []string{"ADD_COMMENTS"}, wantErr: "ADD_COMMENTS"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := permissionsMustExist(got, "OP", tc.permissions)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("want no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("want error mentioning %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewComments(t *testing.T) {
+	got := newComments([]*jira.Comment{
+		{Author: jira.User{DisplayName: "Alice"}, Created: "2023-03-17T15:04:05.000+0100", Body: "Hello"},
+		nil,
+		{Author: jira.User{DisplayName: "Bob"}, Created: "yesterday", Body: "Secret", Visibility: jira.CommentVisibility{Type: "role", Value: "Administrators"}},
+	})
+	want := []Comment{
+		{Author: "Alice", Created: time.Date(2023, 3, 17, 14, 4, 5, 0, time.UTC), Body: "Hello"},
+		{Author: "Bob", Body: "Secret", Restricted: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d comments, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Author != want[i].Author || !got[i].Created.Equal(want[i].Created) ||
+			got[i].Body != want[i].Body || got[i].Restricted != want[i].Restricted {
+			t.Errorf("comment %d: want %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestLinkTypeMustExist(t *testing.T) {
+	linkTypes := []jira.IssueLinkType{{Name: "Blocks"}, {Name: "Relates"}}
+	if err := linkTypeMustExist(linkTypes, "Relates"); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	err := linkTypeMustExist(linkTypes, "Duplicate")
+	if err == nil || !strings.Contains(err.Error(), "Blocks, Relates") {
+		t.Errorf("want error listing the link types, got %v", err)
+	}
+}
+
+func TestSecurityLevelMustExist(t *testing.T) {
+	var got projectSecurityLevels
+	err := json.Unmarshal([]byte(`{"levels": [
+		{"id": "10000", "name": "Internal"},
+		{"id": "10001", "name": "Restricted"}
+	]}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		levels  projectSecurityLevels
+		level   string
+		wantErr string
+	}{
+		{name: "found", levels: got, level: "Internal"},
+		{name: "case insensitive", levels: got, level: "restricted"},
+		{name: "missing", levels: got, level: "Secret", wantErr: "Internal, Restricted"},
+		{name: "no levels", level: "Internal", wantErr: "Set Issue Security"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := securityLevelMustExist(tc.levels, "OP", tc.level)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("want no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("want error mentioning %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig_caCertFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := newTLSConfig(&config.Jira{URL: srv.URL, CACertFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request with custom CA: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewTLSConfig_invalidCACertFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTLSConfig(&config.Jira{CACertFile: caFile}); err == nil {
+		t.Error("want error for file without PEM certificates, got nil")
+	}
+	if _, err := newTLSConfig(&config.Jira{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("want error for missing file, got nil")
+	}
+}
+
+func TestNew_userAgent(t *testing.T) {
+	var gotUserAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"jelease"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(&config.Jira{
+		URL:       srv.URL,
+		UserAgent: "jelease",
+		Auth:      config.JiraAuth{Type: config.JiraAuthTypePAT, Token: "abc123"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "jelease/" + AppVersion
+	if gotUserAgent != want {
+		t.Errorf("want User-Agent %q, got %q", want, gotUserAgent)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Jira
+		want time.Duration
+	}{
+		{name: "no timeouts", want: 0},
+		{name: "timeout", cfg: config.Jira{Timeout: config.Duration(30 * time.Second)}, want: 30 * time.Second},
+		{
+			name: "operation timeout above 10s",
+			cfg: config.Jira{
+				Timeout:           config.Duration(5 * time.Second),
+				OperationTimeouts: config.JiraOperationTimeouts{Search: config.Duration(45 * time.Second), Create: config.Duration(15 * time.Second)},
+			},
+			want: 45 * time.Second,
+		},
+		{
+			name: "operation timeout without timeout",
+			cfg:  config.Jira{OperationTimeouts: config.JiraOperationTimeouts{Update: config.Duration(20 * time.Second)}},
+			want: 20 * time.Second,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := clientTimeout(&tc.cfg); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	defaults := newTransport(&config.JiraTransport{}, nil)
+	if defaults.MaxIdleConns != 100 || defaults.MaxIdleConnsPerHost != 0 || defaults.IdleConnTimeout != 90*time.Second {
+		t.Errorf("want Go's default transport settings, got maxIdleConns=%d maxIdleConnsPerHost=%d idleConnTimeout=%s",
+			defaults.MaxIdleConns, defaults.MaxIdleConnsPerHost, defaults.IdleConnTimeout)
+	}
+
+	tuned := newTransport(&config.JiraTransport{
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     config.Duration(30 * time.Second),
+	}, nil)
+	if tuned.MaxIdleConns != 50 || tuned.MaxIdleConnsPerHost != 20 || tuned.IdleConnTimeout != 30*time.Second {
+		t.Errorf("want tuned transport settings, got maxIdleConns=%d maxIdleConnsPerHost=%d idleConnTimeout=%s",
+			tuned.MaxIdleConns, tuned.MaxIdleConnsPerHost, tuned.IdleConnTimeout)
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultMaxErrorBodyLength is used when the config has no limit set.
+const defaultMaxErrorBodyLength = 512
+
+// responseError wraps the error together with a concise summary of Jira's
+// response, such as when Jira is behind an SSO proxy that responds with an
+// HTML login page instead of JSON.
+func (c *client) responseError(errCtx string, resp *jira.Response, err error) error {
+	if resp == nil {
+		return fmt.Errorf("%s: %w", errCtx, err)
+	}
+	return fmt.Errorf("%s: %w. Response: %s", errCtx, err, c.summarizeResponse(resp))
+}
+
+// summarizeResponse returns the status code, content type, and the first
+// bytes of the response body. The response body is left intact for later use.
+func (c *client) summarizeResponse(resp *jira.Response) string {
+	contentType := resp.Header.Get("Content-Type")
+	body, err := c.peekBody(resp)
+	if err != nil {
+		return fmt.Sprintf("status %d, content type %q, failed to read body: %v",
+			resp.StatusCode, contentType, err)
+	}
+	return fmt.Sprintf("status %d, content type %q, body: %s",
+		resp.StatusCode, contentType, c.truncateBody(body))
+}
+
+func (c *client) logJiraErrResponse(resp *jira.Response, err error) {
+	if resp == nil {
+		log.Error().Err(err).Msg("Failed Jira request.")
+		return
+	}
+	contentType := resp.Header.Get("Content-Type")
+	ev := log.Error().Err(err).
+		Int("status", resp.StatusCode).
+		Str("contentType", contentType)
+	body, readErr := c.peekBody(resp)
+	if readErr != nil {
+		ev.AnErr("readErr", readErr).Msg("Failed Jira request, and to read Jira response body.")
+		return
+	}
+	var obj any
+	if isJSONContentType(contentType) && json.Unmarshal(body, &obj) == nil {
+		ev.Interface("body", obj).Msg("Failed Jira request.")
+		return
+	}
+	ev.Str("body", c.truncateBody(body)).Msg("Failed Jira request.")
+}
+
+func (c *client) peekBody(resp *jira.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+func (c *client) truncateBody(body []byte) string {
+	maxLen := int(c.cfg.MaxErrorBodyLength)
+	if maxLen == 0 {
+		maxLen = defaultMaxErrorBodyLength
+	}
+	return truncate(string(body), maxLen)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return fmt.Sprintf("%s... (truncated %d bytes)", s[:maxLen], len(s)-maxLen)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
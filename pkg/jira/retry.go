@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRetryDelay caps the delay between retries, including delays requested
+// by Jira via the Retry-After header.
+const maxRetryDelay = 30 * time.Second
+
+// RateLimitError is returned when Jira still responds with 429 Too Many
+// Requests after all retries, or when the context is done while waiting to
+// retry after a 429, in which case it also wraps the context's error.
+type RateLimitError struct {
+	RetryAfter time.Duration // zero if Jira did not send a Retry-After header
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// doWithRetry calls the function, and retries it with an exponential backoff
+// if it failed with an error that is likely to be temporary. Stops retrying
+// when the context is done.
+func (c *client) doWithRetry(ctx context.Context, operation string, fn func(ctx context.Context) (*jira.Response, error)) (*jira.Response, error) {
+	if timeout := c.operationTimeout(operation); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := fn(ctx)
+		observeRequest(operation, resp, start)
+		if err == nil || attempt >= int(c.cfg.Retry.MaxRetries) || !shouldRetry(resp, err) {
+			if err != nil {
+				if rateLimitErr := newRateLimitError(resp, err); rateLimitErr != nil {
+					return resp, rateLimitErr
+				}
+			}
+			return resp, err
+		}
+		delay := retryDelay(attempt, c.cfg.Retry.BaseDelay.Duration(), resp)
+		log.Warn().Err(err).
+			Str("operation", operation).
+			Int("attempt", attempt+1).
+			Dur("delay", delay).
+			Msg("Failed Jira request. Retrying.")
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if rateLimitErr := newRateLimitError(resp, fmt.Errorf("%w while waiting to retry: %v", ctx.Err(), err)); rateLimitErr != nil {
+				return nil, rateLimitErr
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newRateLimitError wraps the error in a [RateLimitError] if Jira responded
+// with 429 Too Many Requests, and returns nil otherwise.
+func newRateLimitError(resp *jira.Response, err error) *RateLimitError {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+	return &RateLimitError{RetryAfter: retryAfter, Err: err}
+}
+
+// operationTimeout returns the timeout for the operation, including retries,
+// as configured via [config.Jira.OperationTimeouts]. Returns 0 if unset, in
+// which case only the overall [config.Jira.Timeout] of the webhook applies.
+func (c *client) operationTimeout(operation string) time.Duration {
+	timeouts := c.cfg.OperationTimeouts
+	switch operation {
+	case "search":
+		return timeouts.Search.Duration()
+	case "create":
+		return timeouts.Create.Duration()
+	case "update", "watcher", "link":
+		return timeouts.Update.Duration()
+	default:
+		return 0
+	}
+}
+
+// shouldRetry returns true on network errors, on rate limiting (429),
+// and on server errors (5xx). Client errors (4xx) are never retried.
+func shouldRetry(resp *jira.Response, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if resp == nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryDelay returns the exponential backoff delay with jitter for the
+// given attempt (zero-based), or the delay from the Retry-After header if
+// Jira sent one together with a 429 response.
+func retryDelay(attempt int, baseDelay time.Duration, resp *jira.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return minDuration(delay, maxRetryDelay)
+		}
+	}
+	if baseDelay <= 0 {
+		return 0
+	}
+	backoff := maxRetryDelay
+	// Compared before shifting, as the shift can overflow
+	if baseDelay <= maxRetryDelay>>attempt {
+		backoff = baseDelay << attempt
+	}
+	// Random delay between 50% and 100% of the backoff
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses the Retry-After HTTP header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
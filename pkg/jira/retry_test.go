@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestDoWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   uint
+		statusCodes  []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "success",
+			maxRetries:   3,
+			statusCodes:  []int{200},
+			wantAttempts: 1,
+		},
+		{
+			name:         "server error then success",
+			maxRetries:   3,
+			statusCodes:  []int{503, 502, 200},
+			wantAttempts: 3,
+		},
+		{
+			name:         "rate limited then success",
+			maxRetries:   3,
+			statusCodes:  []int{429, 200},
+			wantAttempts: 2,
+		},
+		{
+			name:         "network error then success",
+			maxRetries:   3,
+			statusCodes:  []int{0, 200},
+			wantAttempts: 2,
+		},
+		{
+			name:         "client error",
+			maxRetries:   3,
+			statusCodes:  []int{400, 200},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "retries exhausted",
+			maxRetries:   2,
+			statusCodes:  []int{500, 500, 500, 200},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{cfg: &config.Jira{
+				Retry: config.JiraRetry{
+					MaxRetries: tc.maxRetries,
+					BaseDelay:  config.Duration(time.Millisecond),
+				},
+			}}
+			var attempts int
+			_, err := c.doWithRetry(context.Background(), "test", func(ctx context.Context) (*jira.Response, error) {
+				code := tc.statusCodes[attempts]
+				attempts++
+				if code == 0 {
+					return nil, errors.New("connection refused")
+				}
+				resp := &jira.Response{Response: &http.Response{StatusCode: code, Header: http.Header{}}}
+				if code >= 400 {
+					return resp, errors.New(http.StatusText(code))
+				}
+				return resp, nil
+			})
+			if tc.wantErr && err == nil {
+				t.Error("want error, got nil")
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("want no error, got: %s", err)
+			}
+			if attempts != tc.wantAttempts {
+				t.Errorf("want %d attempts, got %d", tc.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestDoWithRetry_contextDone(t *testing.T) {
+	c := &client{cfg: &config.Jira{
+		Retry: config.JiraRetry{
+			MaxRetries: 3,
+			BaseDelay:  config.Duration(time.Hour),
+		},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var attempts int
+	_, err := c.doWithRetry(ctx, "test", func(ctx context.Context) (*jira.Response, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded error, got: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("want 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDoWithRetry_operationTimeout(t *testing.T) {
+	c := &client{cfg: &config.Jira{
+		Retry: config.JiraRetry{
+			MaxRetries: 3,
+			BaseDelay:  config.Duration(time.Hour),
+		},
+		OperationTimeouts: config.JiraOperationTimeouts{
+			Search: config.Duration(10 * time.Millisecond),
+		},
+	}}
+	tests := []struct {
+		operation    string
+		wantDeadline bool
+	}{
+		{operation: "search", wantDeadline: true},
+		{operation: "create", wantDeadline: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.operation, func(t *testing.T) {
+			var hasDeadline bool
+			_, err := c.doWithRetry(context.Background(), tc.operation, func(ctx context.Context) (*jira.Response, error) {
+				_, hasDeadline = ctx.Deadline()
+				if hasDeadline {
+					return nil, errors.New("connection refused")
+				}
+				return nil, nil
+			})
+			if hasDeadline != tc.wantDeadline {
+				t.Errorf("want deadline %t, got %t", tc.wantDeadline, hasDeadline)
+			}
+			if tc.wantDeadline && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("want deadline exceeded error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDoWithRetry_rateLimited(t *testing.T) {
+	c := &client{cfg: &config.Jira{
+		Retry: config.JiraRetry{MaxRetries: 1},
+	}}
+	var attempts int
+	_, err := c.doWithRetry(context.Background(), "test", func(ctx context.Context) (*jira.Response, error) {
+		attempts++
+		resp := &jira.Response{Response: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"0"}},
+		}}
+		return resp, errors.New(http.StatusText(http.StatusTooManyRequests))
+	})
+	if attempts != 2 {
+		t.Errorf("want 2 attempts, got %d", attempts)
+	}
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("want rate limit error, got: %v", err)
+	}
+}
+
+func TestDoWithRetry_rateLimitedUntilDeadline(t *testing.T) {
+	c := &client{cfg: &config.Jira{
+		Retry: config.JiraRetry{MaxRetries: 3},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var attempts int
+	_, err := c.doWithRetry(ctx, "test", func(ctx context.Context) (*jira.Response, error) {
+		attempts++
+		resp := &jira.Response{Response: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"20"}},
+		}}
+		return resp, errors.New(http.StatusText(http.StatusTooManyRequests))
+	})
+	if attempts != 1 {
+		t.Errorf("want 1 attempt, got %d", attempts)
+	}
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("want rate limit error, got: %v", err)
+	}
+	if rateLimitErr.RetryAfter != 20*time.Second {
+		t.Errorf("want retry after 20s, got %s", rateLimitErr.RetryAfter)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want deadline exceeded error, got: %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 4; attempt++ {
+		backoff := base << attempt
+		got := retryDelay(attempt, base, nil)
+		if got < backoff/2 || got > backoff {
+			t.Errorf("attempt %d: want delay between %s and %s, got %s", attempt, backoff/2, backoff, got)
+		}
+	}
+
+	got := retryDelay(100, base, nil)
+	if got > maxRetryDelay {
+		t.Errorf("want delay capped at %s, got %s", maxRetryDelay, got)
+	}
+}
+
+func TestRetryDelay_overflow(t *testing.T) {
+	for _, attempt := range []int{2, 31, 40, 63, 64, 100} {
+		got := retryDelay(attempt, 10*time.Second, nil)
+		if got < maxRetryDelay/2 || got > maxRetryDelay {
+			t.Errorf("attempt %d: want delay between %s and %s, got %s", attempt, maxRetryDelay/2, maxRetryDelay, got)
+		}
+	}
+}
+
+func TestRetryDelay_retryAfter(t *testing.T) {
+	resp := &jira.Response{Response: &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"7"}},
+	}}
+	got := retryDelay(0, time.Millisecond, resp)
+	if got != 7*time.Second {
+		t.Errorf("want %s, got %s", 7*time.Second, got)
+	}
+}
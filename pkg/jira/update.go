@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+// issueUpdate builds the body for Jira's "edit issue" endpoint, using
+// update operations on the fields, such as "set" and "add". See:
+// https://developer.atlassian.com/server/jira/platform/jira-rest-api-examples/#editing-an-issue-examples
+//
+// NOTE: go-jira's Issue.Update method is not used, as it sends the issue's
+// "fields" which replaces the field values, and so cannot express operations
+// like adding labels without removing the existing ones.
+type issueUpdate map[string][]map[string]any
+
+// set adds an operation that replaces the field's value.
+func (u issueUpdate) set(field string, value any) issueUpdate {
+	u[field] = append(u[field], map[string]any{"set": value})
+	return u
+}
+
+// add adds an operation that adds a value to a multi-value field, such as
+// labels or comments, keeping the existing values.
+func (u issueUpdate) add(field string, value any) issueUpdate {
+	u[field] = append(u[field], map[string]any{"add": value})
+	return u
+}
+
+// body returns the request body for go-jira's Issue.UpdateIssue method.
+func (u issueUpdate) body() map[string]any {
+	return map[string]any{"update": map[string][]map[string]any(u)}
+}
+
+// newSetSummaryUpdate creates the body for Jira's "edit issue" endpoint that
+// replaces the summary.
+func newSetSummaryUpdate(summary string) map[string]any {
+	return issueUpdate{}.set("summary", summary).body()
+}
+
+// newAddLabelsUpdate creates the body for Jira's "edit issue" endpoint that
+// uses the "add" operation, as opposed to setting the fields, so that no
+// existing labels are removed.
+func newAddLabelsUpdate(labels []string) map[string]any {
+	update := issueUpdate{}
+	for _, label := range labels {
+		update.add("labels", label)
+	}
+	return update.body()
+}
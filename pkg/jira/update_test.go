@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIssueUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update map[string]any
+		want   string
+	}{
+		{
+			name:   "set summary",
+			update: newSetSummaryUpdate(`Update "neuvector" to version v5.1.3`),
+			want:   `{"update":{"summary":[{"set":"Update \"neuvector\" to version v5.1.3"}]}}`,
+		},
+		{
+			name:   "add labels",
+			update: newAddLabelsUpdate([]string{"jelease", "neuvector"}),
+			want:   `{"update":{"labels":[{"add":"jelease"},{"add":"neuvector"}]}}`,
+		},
+		{
+			name:   "no labels",
+			update: newAddLabelsUpdate(nil),
+			want:   `{"update":{}}`,
+		},
+		{
+			name: "combined",
+			update: issueUpdate{}.
+				set("summary", "Update neuvector to version v5.1.3").
+				add("labels", "jelease").
+				body(),
+			want: `{"update":{"labels":[{"add":"jelease"}],"summary":[{"set":"Update neuvector to version v5.1.3"}]}}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.want {
+				t.Errorf("want %s, got %s", tc.want, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Webhook results, used as the "result" label value.
+const (
+	WebhookResultCreated     = "created"
+	WebhookResultUpdated     = "updated"
+	WebhookResultSkipped     = "skipped"
+	WebhookResultBadRequest  = "bad_request"
+	WebhookResultRejected    = "rejected"
+	WebhookResultRateLimited = "rate_limited"
+	WebhookResultError       = "error"
+)
+
+var (
+	webhooksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "jelease",
+		Name:      "webhooks_total",
+		Help:      "Number of received webhooks, partitioned by result.",
+	}, []string{"result"})
+
+	jiraRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "jelease",
+		Name:      "jira_requests_total",
+		Help:      "Number of requests sent to Jira, partitioned by operation and HTTP status code.",
+	}, []string{"operation", "status"})
+
+	jiraRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "jelease",
+		Name:      "jira_request_duration_seconds",
+		Help:      "Latency of requests sent to Jira, partitioned by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	versionsCreatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "jelease",
+		Name:      "versions_created_total",
+		Help:      "Number of versions created in Jira projects, partitioned by project.",
+	}, []string{"project"})
+)
+
+// IncWebhook increments the counter of received webhooks.
+func IncWebhook(result string) {
+	webhooksTotal.WithLabelValues(result).Inc()
+}
+
+// IncVersionCreated increments the counter of versions created in Jira,
+// such as via Config.Jira.Issue.CreateMissingVersions.
+func IncVersionCreated(projectKey string) {
+	versionsCreatedTotal.WithLabelValues(projectKey).Inc()
+}
+
+// ObserveJiraRequest records a request sent to Jira. The status code is
+// set to 0 if no response was received, such as on network errors.
+func ObserveJiraRequest(operation string, statusCode int, start time.Time) {
+	jiraRequestsTotal.WithLabelValues(operation, strconv.Itoa(statusCode)).Inc()
+	jiraRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
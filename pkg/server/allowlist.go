@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/RiskIdent/jelease/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// parseAllowedIPs parses the CIDRs from [config.HTTP.AllowedIPs]. Invalid
+// CIDRs are skipped, as they are already rejected when validating the config.
+func parseAllowedIPs(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// ipAllowlistMiddleware rejects requests with 403 Forbidden when the source
+// IP is not in any of the allowed prefixes.
+func ipAllowlistMiddleware(allowed []netip.Prefix, trustProxy bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip, ok := clientIPAddr(c, trustProxy)
+		if !ok || !containsIP(allowed, ip) {
+			log.Warn().
+				Str("remoteAddr", c.Request.RemoteAddr).
+				Str("sourceIp", ip.String()).
+				Msg("Rejected webhook request from IP not in allowlist.")
+			metrics.IncWebhook(metrics.WebhookResultRejected)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "source IP not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func containsIP(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPAllowlistMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		trustProxy   bool
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{name: "allowed", remoteAddr: "192.0.2.10:1234", wantStatus: http.StatusOK},
+		{name: "allowed ipv6", remoteAddr: "[2001:db8::1]:1234", wantStatus: http.StatusOK},
+		{name: "denied", remoteAddr: "198.51.100.1:1234", wantStatus: http.StatusForbidden},
+		{name: "forwarded ignored without trust", remoteAddr: "198.51.100.1:1234", forwardedFor: "192.0.2.10", wantStatus: http.StatusForbidden},
+		{name: "proxied allowed", trustProxy: true, remoteAddr: "10.0.0.1:1234", forwardedFor: "192.0.2.10", wantStatus: http.StatusOK},
+		{name: "proxied denied", trustProxy: true, remoteAddr: "10.0.0.1:1234", forwardedFor: "198.51.100.1", wantStatus: http.StatusForbidden},
+		{name: "proxied spoofed", trustProxy: true, remoteAddr: "10.0.0.1:1234", forwardedFor: "192.0.2.10, 198.51.100.1", wantStatus: http.StatusForbidden},
+		{name: "proxied invalid", trustProxy: true, remoteAddr: "192.0.2.10:1234", forwardedFor: "unknown", wantStatus: http.StatusForbidden},
+		{name: "proxied without header", trustProxy: true, remoteAddr: "192.0.2.10:1234", wantStatus: http.StatusOK},
+	}
+	allowed := parseAllowedIPs([]string{"192.0.2.0/24", "2001:db8::/32"})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/", ipAllowlistMiddleware(allowed, tc.trustProxy), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, w.Code)
+			}
+		})
+	}
+}
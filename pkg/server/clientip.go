@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clientIPKey = "jelease/clientIP"
+
+// clientIPMiddleware resolves the client IP of each request once, so the
+// request logs, rate limit and IP allowlist all agree on the same address.
+//
+// This is used instead of gin's [gin.Context.ClientIP], which by default
+// trusts the X-Forwarded-For header from any peer.
+func clientIPMiddleware(trustProxy bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip, ok := sourceIP(c.Request, trustProxy); ok {
+			c.Set(clientIPKey, ip)
+		}
+		c.Next()
+	}
+}
+
+// clientIP returns the client IP resolved by [clientIPMiddleware], for use in
+// logs. Falls back to the connection's address when the middleware is not
+// used or the address could not be parsed.
+func clientIP(c *gin.Context) string {
+	if ip, ok := c.Value(clientIPKey).(netip.Addr); ok {
+		return ip.String()
+	}
+	if host, _, err := net.SplitHostPort(c.Request.RemoteAddr); err == nil {
+		return host
+	}
+	return c.Request.RemoteAddr
+}
+
+// clientIPAddr returns the client IP resolved by [clientIPMiddleware], or
+// resolves it from the request when the middleware is not used.
+func clientIPAddr(c *gin.Context, trustProxy bool) (netip.Addr, bool) {
+	if ip, ok := c.Value(clientIPKey).(netip.Addr); ok {
+		return ip, true
+	}
+	return sourceIP(c.Request, trustProxy)
+}
+
+// sourceIP returns the IP the request was sent from. When trustProxy is set,
+// then the last address in the X-Forwarded-For header is used, which is the
+// one added by the reverse proxy in front of jelease. Earlier addresses in
+// the header are ignored, as clients can set them to anything. Without
+// X-Forwarded-For, the X-Real-IP header is used instead.
+func sourceIP(r *http.Request, trustProxy bool) (netip.Addr, bool) {
+	if trustProxy {
+		if header := r.Header.Values("X-Forwarded-For"); len(header) > 0 {
+			forwarded := strings.Split(header[len(header)-1], ",")
+			return parseAddr(forwarded[len(forwarded)-1])
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return parseAddr(realIP)
+		}
+	}
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addrPort.Addr().Unmap(), true
+}
+
+func parseAddr(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	return addr.Unmap(), err == nil
+}
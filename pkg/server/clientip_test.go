@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		remoteAddr string
+		headers    map[string]string
+		want       string
+		wantOK     bool
+	}{
+		{name: "remote addr", remoteAddr: "192.0.2.10:1234", want: "192.0.2.10", wantOK: true},
+		{name: "ipv4 mapped", remoteAddr: "[::ffff:192.0.2.10]:1234", want: "192.0.2.10", wantOK: true},
+		{name: "invalid remote addr", remoteAddr: "pipe", wantOK: false},
+		{name: "forwarded ignored", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "192.0.2.10"}, want: "10.0.0.1", wantOK: true},
+		{name: "real ip ignored", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Real-IP": "192.0.2.10"}, want: "10.0.0.1", wantOK: true},
+		{name: "proxied forwarded", trustProxy: true, remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "192.0.2.10"}, want: "192.0.2.10", wantOK: true},
+		{name: "proxied forwarded last", trustProxy: true, remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 192.0.2.10"}, want: "192.0.2.10", wantOK: true},
+		{name: "proxied forwarded before real ip", trustProxy: true, remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "192.0.2.10", "X-Real-IP": "198.51.100.1"}, want: "192.0.2.10", wantOK: true},
+		{name: "proxied real ip", trustProxy: true, remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Real-IP": "192.0.2.10"}, want: "192.0.2.10", wantOK: true},
+		{name: "proxied invalid", trustProxy: true, remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Real-IP": "unknown"}, wantOK: false},
+		{name: "proxied without headers", trustProxy: true, remoteAddr: "10.0.0.1:1234", want: "10.0.0.1", wantOK: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			got, ok := sourceIP(req, tc.trustProxy)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok %t, got %t", tc.wantOK, ok)
+			}
+			if ok && got.String() != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		want       string
+	}{
+		{name: "direct", want: "10.0.0.1"},
+		{name: "proxied", trustProxy: true, want: "192.0.2.10"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			var got string
+			r.Use(clientIPMiddleware(tc.trustProxy))
+			r.GET("/", func(c *gin.Context) {
+				got = clientIP(c)
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", "192.0.2.10")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
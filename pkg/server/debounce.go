@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/version"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+)
+
+// releaseDebouncer delays processing releases, so that releases of the same
+// project received in quick succession are collapsed into one, using a timer
+// per project that restarts with each new release.
+type releaseDebouncer struct {
+	delay   time.Duration
+	process func(Release)
+
+	mu      sync.Mutex
+	pending map[debounceKey]*pendingRelease
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+type debounceKey struct {
+	provider string
+	project  string
+}
+
+type pendingRelease struct {
+	release Release
+	timer   *time.Timer
+}
+
+func newReleaseDebouncer(delay time.Duration, process func(Release)) *releaseDebouncer {
+	return &releaseDebouncer{
+		delay:   delay,
+		process: process,
+		pending: make(map[debounceKey]*pendingRelease),
+	}
+}
+
+// add schedules the release to be processed after the delay. If a release of
+// the same project is already pending, then the two are collapsed and the
+// delay restarts.
+func (d *releaseDebouncer) add(release Release) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		d.wg.Add(1)
+		go d.run(release)
+		return
+	}
+	key := debounceKey{provider: release.Provider, project: release.Project}
+	if p, ok := d.pending[key]; ok {
+		log.Debug().
+			EmbedObject(release).
+			Str("pendingVersion", p.release.Version).
+			Msg("Collapsed release into pending release.")
+		p.release = collapseReleases(p.release, release)
+		// If the timer already fired, then its func is waiting for the lock
+		// and will process the collapsed release.
+		if p.timer.Stop() {
+			p.timer.Reset(d.delay)
+		}
+		return
+	}
+	log.Debug().
+		EmbedObject(release).
+		Dur("delay", d.delay).
+		Msg("Debouncing release.")
+	p := &pendingRelease{release: release}
+	d.wg.Add(1)
+	p.timer = time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		if d.pending[key] == p {
+			delete(d.pending, key)
+		}
+		release := p.release
+		d.mu.Unlock()
+		d.run(release)
+	})
+	d.pending[key] = p
+}
+
+func (d *releaseDebouncer) run(release Release) {
+	defer d.wg.Done()
+	d.process(release)
+}
+
+// flush processes all pending releases right away, without waiting for their
+// delay, and waits for them to finish. Releases added afterwards are
+// processed right away.
+func (d *releaseDebouncer) flush(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	log.Info().Int("pending", len(d.pending)).Msg("Flushing debounced releases.")
+	for key, p := range d.pending {
+		// If the timer already fired, then its func processes the release.
+		if p.timer.Stop() {
+			delete(d.pending, key)
+			go d.run(p.release)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// collapseReleases returns the release with the latest version, falling back
+// to the most recently received one when the versions cannot be compared.
+// The CVEs of both are kept, so any security fixes are still mentioned.
+func collapseReleases(pending, next Release) Release {
+	latest := next
+	pendingVer, err1 := version.Parse(pending.Version)
+	nextVer, err2 := version.Parse(next.Version)
+	if err1 == nil && err2 == nil && nextVer.Compare(pendingVer) < 0 {
+		latest = pending
+	}
+	var cves []string
+	for _, cve := range append(append([]string(nil), pending.CVE...), next.CVE...) {
+		if !slices.Contains(cves, cve) {
+			cves = append(cves, cve)
+		}
+	}
+	latest.CVE = cves
+	return latest
+}
+
+// processDebouncedRelease processes a release once its debounce delay has
+// passed, via the queue when enabled. As the webhook has already been
+// responded to, a release that does not fit in the queue is only logged,
+// instead of blocking until the queue has room.
+func (s HTTPServer) processDebouncedRelease(release Release) {
+	if s.queue != nil {
+		s.queue.tryEnqueue(release)
+		return
+	}
+	ctx, cancel := s.jiraContext(context.Background())
+	// Failures are logged by processReleases, as they cannot be returned to
+	// newreleases.io anymore.
+	results := s.processReleases(ctx, []Release{release})
+	cancel()
+	s.startFollowUps(results)
+}
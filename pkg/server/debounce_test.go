@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+type processedReleases struct {
+	mu       sync.Mutex
+	releases []Release
+}
+
+func (p *processedReleases) process(release Release) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.releases = append(p.releases, release)
+}
+
+func TestReleaseDebouncer_flush(t *testing.T) {
+	var processed processedReleases
+	d := newReleaseDebouncer(time.Hour, processed.process)
+	d.add(Release{Provider: "npm", Project: "vite", Version: "4.2.0"})
+	d.add(Release{Provider: "npm", Project: "vite", Version: "4.2.1", CVE: []string{"CVE-2023-1234"}})
+	d.add(Release{Provider: "npm", Project: "vite", Version: "4.2.2"})
+	d.add(Release{Provider: "github", Project: "vite", Version: "4.0.0"})
+
+	if err := d.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(processed.releases) != 2 {
+		t.Fatalf("want 2 processed releases, got %d: %v", len(processed.releases), processed.releases)
+	}
+	slices.SortFunc(processed.releases, func(a, b Release) bool { return a.Provider < b.Provider })
+	if got := processed.releases[0].Version; got != "4.0.0" {
+		t.Errorf("want github version 4.0.0, got %q", got)
+	}
+	npm := processed.releases[1]
+	if npm.Version != "4.2.2" {
+		t.Errorf("want npm version 4.2.2, got %q", npm.Version)
+	}
+	if !slices.Equal(npm.CVE, []string{"CVE-2023-1234"}) {
+		t.Errorf("want CVE of collapsed release kept, got %v", npm.CVE)
+	}
+
+	d.add(Release{Provider: "npm", Project: "vite", Version: "4.2.3"})
+	if err := d.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(processed.releases) != 3 {
+		t.Errorf("want release added after flush processed right away, got %d releases", len(processed.releases))
+	}
+}
+
+func TestReleaseDebouncer_delay(t *testing.T) {
+	done := make(chan Release, 2)
+	d := newReleaseDebouncer(10*time.Millisecond, func(r Release) { done <- r })
+	d.add(Release{Project: "redis", Version: "7.0.8"})
+	d.add(Release{Project: "redis", Version: "7.0.9"})
+
+	select {
+	case got := <-done:
+		if got.Version != "7.0.9" {
+			t.Errorf("want version 7.0.9, got %q", got.Version)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for debounced release")
+	}
+	if err := d.flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 0 {
+		t.Errorf("want release processed only once, got %d more", len(done))
+	}
+}
+
+func TestCollapseReleases(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending Release
+		next    Release
+		want    string
+		wantCVE []string
+	}{
+		{
+			name:    "newer",
+			pending: Release{Version: "v1.2.3", CVE: []string{"CVE-1"}},
+			next:    Release{Version: "v1.2.4", CVE: []string{"CVE-1", "CVE-2"}},
+			want:    "v1.2.4",
+			wantCVE: []string{"CVE-1", "CVE-2"},
+		},
+		{
+			name:    "older backport",
+			pending: Release{Version: "v2.0.1"},
+			next:    Release{Version: "v1.9.8", CVE: []string{"CVE-2"}},
+			want:    "v2.0.1",
+			wantCVE: []string{"CVE-2"},
+		},
+		{
+			name:    "unparsable uses latest received",
+			pending: Release{Version: "2023-03-17"},
+			next:    Release{Version: "nightly"},
+			want:    "nightly",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collapseReleases(tc.pending, tc.next)
+			if got.Version != tc.want {
+				t.Errorf("want version %q, got %q", tc.want, got.Version)
+			}
+			if !slices.Equal(got.CVE, tc.wantCVE) {
+				t.Errorf("want CVEs %v, got %v", tc.wantCVE, got.CVE)
+			}
+		})
+	}
+}
+
+func TestProcessDebouncedRelease_queueFull(t *testing.T) {
+	s := HTTPServer{queue: newReleaseQueue(1, 1)}
+	s.queue.releases <- Release{Project: "neuvector", Version: "v5.1.3"}
+
+	done := make(chan struct{})
+	go func() {
+		s.processDebouncedRelease(Release{Project: "redis", Version: "7.0.8"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("want release to be dropped when queue is full, but it blocked")
+	}
+	if got := len(s.queue.releases); got != 1 {
+		t.Errorf("want 1 queued release, got %d", got)
+	}
+}
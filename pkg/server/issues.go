@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+)
+
+// managedIssue is an open jelease-managed Jira issue, as returned by the
+// GET /issues endpoint.
+type managedIssue struct {
+	Key     string    `json:"key"`
+	Summary string    `json:"summary"`
+	Project string    `json:"project"`
+	Created time.Time `json:"created"`
+}
+
+// handleGetIssues handles GET requests for the open jelease-managed issues,
+// found by the managed label, optionally filtered by the "project" query
+// parameter. Requests are authenticated the same way as the webhook, where
+// the signature is of the timestamp and the raw query string instead of the
+// body, so the query cannot be tampered with. The timestamp must also be
+// recent, so captured requests cannot be replayed later.
+func (s HTTPServer) handleGetIssues(c *gin.Context) {
+	if !s.hasValidSignature(c, []byte(c.Request.URL.RawQuery)) || !s.hasFreshTimestamp(c, time.Now()) {
+		log.Warn().
+			Str("remoteAddr", clientIP(c)).
+			Msg("Rejected issues request with invalid signature.")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+	if s.cfg.Jira.Issue.ManagedLabel == "" {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "listing issues requires jira.issue.managedLabel to be set"})
+		return
+	}
+
+	ctx, cancel := s.jiraContext(c.Request.Context())
+	defer cancel()
+	jql := newManagedIssuesQuery(s.cfg.Jira.Issue.ManagedLabel, c.Query("project"))
+	clients := []jira.Client{s.jira}
+	for _, j := range s.namedJira {
+		clients = append(clients, j)
+	}
+	issues := []managedIssue{}
+	for _, j := range clients {
+		found, err := j.FindIssuesWithJQL(ctx, jql)
+		if err != nil {
+			writeJiraError(ctx, c, err, gin.H{"error": err.Error()})
+			return
+		}
+		for _, issue := range found {
+			issues = append(issues, managedIssue{
+				Key:     issue.Key,
+				Summary: issue.Summary,
+				Project: issue.ProjectKey,
+				Created: issue.Created,
+			})
+		}
+	}
+	slices.SortStableFunc(issues, func(a, b managedIssue) bool {
+		return a.Created.After(b.Created)
+	})
+	c.JSON(http.StatusOK, issues)
+}
+
+// newManagedIssuesQuery returns the JQL query for the open issues with the
+// managed label, optionally in a given Jira project.
+func newManagedIssuesQuery(managedLabel, projectKey string) string {
+	filterClause := fmt.Sprintf("labels = %s and statusCategory != Done", jira.QuoteJQL(managedLabel))
+	if projectKey != "" {
+		filterClause += fmt.Sprintf(" and project = %s", jira.QuoteJQL(projectKey))
+	}
+	return filterClause + " ORDER BY created DESC"
+}
@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewManagedIssuesQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		want    string
+	}{
+		{name: "all projects", want: `labels = "jelease" and statusCategory != Done ORDER BY created DESC`},
+		{name: "one project", project: "OP", want: `labels = "jelease" and statusCategory != Done and project = "OP" ORDER BY created DESC`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newManagedIssuesQuery("jelease", tc.project)
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleGetIssues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	created := time.Date(2023, 3, 30, 12, 0, 0, 0, time.UTC)
+	j := &fakeJira{
+		existingIssues: []jira.Issue{
+			{Key: "OP-1", Summary: "Update neuvector to version v5.1.3", ProjectKey: "OP", Created: created},
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ManagedLabel = "jelease"
+	s := HTTPServer{cfg: cfg, jira: j}
+	r := gin.New()
+	r.GET("/issues", s.handleGetIssues)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/issues?project=OP", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	wantJQL := `labels = "jelease" and statusCategory != Done and project = "OP" ORDER BY created DESC`
+	if j.jql != wantJQL {
+		t.Errorf("want jql %q, got %q", wantJQL, j.jql)
+	}
+	var got []managedIssue
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := managedIssue{Key: "OP-1", Summary: "Update neuvector to version v5.1.3", Project: "OP", Created: created}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("want %v, got %v", []managedIssue{want}, got)
+	}
+}
+
+func TestHandleGetIssues_rejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name         string
+		secret       string
+		managedLabel string
+		want         int
+	}{
+		{name: "invalid signature", secret: "s3cr3t", managedLabel: "jelease", want: http.StatusUnauthorized},
+		{name: "no managed label", want: http.StatusNotImplemented},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.HTTP.WebhookSecret = tc.secret
+			cfg.Jira.Issue.ManagedLabel = tc.managedLabel
+			s := HTTPServer{cfg: cfg, jira: &fakeJira{}}
+			r := gin.New()
+			r.GET("/issues", s.handleGetIssues)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/issues", nil))
+			if w.Code != tc.want {
+				t.Errorf("want status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetIssues_signature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "s3cr3t"
+	now := time.Now()
+	sign := func(timestamp time.Time, query string) (string, string) {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(ts + "." + query))
+		return ts, hex.EncodeToString(mac.Sum(nil))
+	}
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		signed    string
+		query     string
+		want      int
+	}{
+		{name: "valid", timestamp: now, signed: "project=OP", query: "project=OP", want: http.StatusOK},
+		{name: "tampered project", timestamp: now, signed: "project=OP", query: "project=OTHER", want: http.StatusUnauthorized},
+		{name: "replayed stale signature", timestamp: now.Add(-time.Hour), signed: "project=OP", query: "project=OP", want: http.StatusUnauthorized},
+		{name: "future timestamp", timestamp: now.Add(time.Hour), signed: "project=OP", query: "project=OP", want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.HTTP.WebhookSecret = secret
+			cfg.Jira.Issue.ManagedLabel = "jelease"
+			s := HTTPServer{cfg: cfg, jira: &fakeJira{}}
+			r := gin.New()
+			r.GET("/issues", s.issuesHandlers()...)
+
+			timestamp, signature := sign(tc.timestamp, tc.signed)
+			req := httptest.NewRequest(http.MethodGet, "/issues?"+tc.query, nil)
+			req.Header.Set(defaultTimestampHeader, timestamp)
+			req.Header.Set(defaultSignatureHeader, signature)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("want status %d, got %d: %s", tc.want, w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import "sync"
+
+// projectLocks serializes the processing of releases per project, so that
+// concurrent webhooks for the same project don't both find no existing issue
+// and then both create one.
+//
+// NOTE: This only works within a single jelease instance. When running
+// multiple replicas, then the webhooks may still race, and the marker label
+// (see Config.Jira.Issue.MarkerLabel) is the only protection against
+// duplicates, as Jira has no way to lock on a search.
+type projectLocks struct {
+	mu    sync.Mutex
+	locks map[string]*projectLock
+}
+
+type projectLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func newProjectLocks() *projectLocks {
+	return &projectLocks{locks: map[string]*projectLock{}}
+}
+
+// lock blocks until the lock for the project is acquired, and returns
+// a function to release it. The lock is removed from the map when no one
+// is holding nor waiting for it, to not grow the map indefinitely.
+// A nil *projectLocks does no locking.
+func (l *projectLocks) lock(project string) (unlock func()) {
+	if l == nil {
+		return func() {}
+	}
+	l.mu.Lock()
+	pl, ok := l.locks[project]
+	if !ok {
+		pl = &projectLock{}
+		l.locks[project] = pl
+	}
+	pl.waiters++
+	l.mu.Unlock()
+
+	pl.Lock()
+	return func() {
+		pl.Unlock()
+		l.mu.Lock()
+		pl.waiters--
+		if pl.waiters == 0 {
+			delete(l.locks, project)
+		}
+		l.mu.Unlock()
+	}
+}
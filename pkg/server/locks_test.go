@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProjectLocks(t *testing.T) {
+	locks := newProjectLocks()
+
+	unlock := locks.lock("neuvector")
+	acquired := make(chan struct{})
+	go func() {
+		unlockAgain := locks.lock("neuvector")
+		close(acquired)
+		unlockAgain()
+	}()
+
+	// Other projects are not blocked
+	locks.lock("redis")()
+
+	select {
+	case <-acquired:
+		t.Fatal("want lock to block while held, but was acquired")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("want all locks to be removed, got %d", len(locks.locks))
+	}
+}
+
+func TestProjectLocks_concurrent(t *testing.T) {
+	locks := newProjectLocks()
+	var wg sync.WaitGroup
+	var inside, maxInside int
+	var mu sync.Mutex
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer locks.lock("neuvector")()
+			mu.Lock()
+			inside++
+			if inside > maxInside {
+				maxInside = inside
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			inside--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if maxInside != 1 {
+		t.Errorf("want at most 1 concurrent holder, got %d", maxInside)
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+	"golang.org/x/time/rate"
+)
+
+// webhookHandlers composes the webhook handler from the middlewares of the
+// enabled features. Each middleware either aborts the request or passes it
+// on to the next, in this order:
+//
+//  1. IP allowlist, so denied IPs do not use up the rate limit
+//  2. Rate limit
+//  3. Signature verification
+//  4. The webhook handler itself
+func (s HTTPServer) webhookHandlers() []gin.HandlerFunc {
+	handlers := s.accessMiddlewares()
+	if s.cfg.HTTP.WebhookSecret != "" {
+		handlers = append(handlers, s.signatureMiddleware)
+	}
+	return append(handlers, s.handlePostWebhook)
+}
+
+// issuesHandlers composes the GET /issues handler from the same IP allowlist
+// and rate limit middlewares as the webhook. The signature is verified by
+// the handler itself, as it is of the query instead of the body.
+func (s HTTPServer) issuesHandlers() []gin.HandlerFunc {
+	return append(s.accessMiddlewares(), s.handleGetIssues)
+}
+
+// accessMiddlewares returns the IP allowlist and rate limit middlewares of
+// the enabled features. Each call gets its own rate limiter, so requests to
+// one endpoint do not use up the rate limit of another.
+func (s HTTPServer) accessMiddlewares() []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if len(s.cfg.HTTP.AllowedIPs) > 0 {
+		handlers = append(handlers, ipAllowlistMiddleware(parseAllowedIPs(s.cfg.HTTP.AllowedIPs), s.cfg.HTTP.TrustProxy))
+	}
+	if limit := s.cfg.HTTP.RateLimit; limit.Rate > 0 {
+		handlers = append(handlers, rateLimitMiddleware(rate.NewLimiter(rate.Limit(limit.Rate), int(limit.Burst))))
+	}
+	return handlers
+}
+
+// signatureMiddleware rejects webhook requests with 401 Unauthorized when
+// the signature does not match the body. The body is restored afterwards,
+// so the next handlers can read it again.
+func (s HTTPServer) signatureMiddleware(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		metrics.IncWebhook(metrics.WebhookResultBadRequest)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !s.hasValidSignature(c, body) {
+		log.Warn().
+			Str("remoteAddr", clientIP(c)).
+			Msg("Rejected webhook request with invalid signature.")
+		metrics.IncWebhook(metrics.WebhookResultRejected)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Next()
+}
+
+// requestLogMiddleware logs each request after it has been handled, except
+// for the skipped paths, such as health checks that are called too often to
+// be of interest. Server errors are logged as warnings.
+func requestLogMiddleware(logger zerolog.Logger, skipPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if slices.Contains(skipPaths, path) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		event := logger.Info()
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			event = logger.Warn()
+		}
+		event.
+			Str("method", c.Request.Method).
+			Str("path", path).
+			Int("status", c.Writer.Status()).
+			Dur("latency", time.Since(start)).
+			Str("remoteAddr", clientIP(c)).
+			Msg("Handled HTTP request.")
+	}
+}
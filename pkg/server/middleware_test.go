@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func TestWebhookHandlers_order(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.HTTP.AllowedIPs = []string{"192.0.2.0/24"}
+	cfg.HTTP.RateLimit.Rate = 0.1
+	cfg.HTTP.RateLimit.Burst = 1
+	cfg.HTTP.WebhookSecret = "my-secret"
+	s := HTTPServer{cfg: cfg}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/", s.webhookHandlers()...)
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// denied IPs are rejected before using up the rate limit
+	if got := send("198.51.100.1:1234"); got != http.StatusForbidden {
+		t.Errorf("want status %d for denied IP, got %d", http.StatusForbidden, got)
+	}
+	if got := send("192.0.2.10:1234"); got != http.StatusUnauthorized {
+		t.Errorf("want status %d for missing signature, got %d", http.StatusUnauthorized, got)
+	}
+	if got := send("192.0.2.10:1234"); got != http.StatusTooManyRequests {
+		t.Errorf("want status %d when exceeding rate limit, got %d", http.StatusTooManyRequests, got)
+	}
+}
+
+func TestIssuesHandlers_order(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.HTTP.AllowedIPs = []string{"192.0.2.0/24"}
+	cfg.HTTP.RateLimit.Rate = 0.1
+	cfg.HTTP.RateLimit.Burst = 1
+	cfg.HTTP.WebhookSecret = "my-secret"
+	s := HTTPServer{cfg: cfg}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/issues", s.issuesHandlers()...)
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/issues", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if got := send("198.51.100.1:1234"); got != http.StatusForbidden {
+		t.Errorf("want status %d for denied IP, got %d", http.StatusForbidden, got)
+	}
+	if got := send("192.0.2.10:1234"); got != http.StatusUnauthorized {
+		t.Errorf("want status %d for missing signature, got %d", http.StatusUnauthorized, got)
+	}
+	if got := send("192.0.2.10:1234"); got != http.StatusTooManyRequests {
+		t.Errorf("want status %d when exceeding rate limit, got %d", http.StatusTooManyRequests, got)
+	}
+}
+
+func TestSignatureMiddleware_restoresBody(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.HTTP.WebhookSecret = "my-secret"
+	s := HTTPServer{cfg: cfg}
+	const (
+		timestamp = "1672502400"
+		body      = `{"provider":"github","project":"neuvector"}`
+		// echo -n "$timestamp.$body" | openssl dgst -sha256 -hmac "$secret"
+		signature = "adadd9fa96dcf3aa2cc0743b47f505146bbd504bdd095b5bbc172121cf2bb900"
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var got []byte
+	r.POST("/", s.signatureMiddleware, func(c *gin.Context) {
+		got, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(defaultSignatureHeader, signature)
+	req.Header.Set(defaultTimestampHeader, timestamp)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, w.Code)
+	}
+	if string(got) != body {
+		t.Errorf("want body %q, got %q", body, got)
+	}
+}
+
+func TestRequestLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestLogMiddleware(zerolog.New(&buf), "/healthz"))
+	r.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+
+	for _, path := range []string{"/healthz", "/fail"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("want 1 log line, as /healthz is skipped, got %d: %q", len(lines), buf.String())
+	}
+	var got struct {
+		Level  string `json:"level"`
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != "warn" || got.Method != http.MethodGet || got.Path != "/fail" || got.Status != http.StatusBadGateway {
+		t.Errorf("unexpected log line: %s", lines[0])
+	}
+}
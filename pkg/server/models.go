@@ -18,33 +18,527 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/RiskIdent/jelease/pkg/util"
+	"github.com/RiskIdent/jelease/pkg/version"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
 )
 
 // Release object unmarshaled from the newreleases.io webhook.
-// Some fields omitted for simplicity, refer to the documentation at https://newreleases.io/webhooks
+// Refer to the documentation at https://newreleases.io/webhooks
+//
+// All documented fields must be declared, even if unused, as the
+// [config.HTTP.StrictPayload] setting rejects payloads with unknown fields.
 type Release struct {
-	Provider string `json:"provider"`
-	Project  string `json:"project"`
-	Version  string `json:"version"`
+	Provider     string          `json:"provider"`
+	Project      string          `json:"project"`
+	Version      string          `json:"version"`
+	Time         time.Time       `json:"time"`
+	CVE          []string        `json:"cve"`
+	IsPrerelease bool            `json:"is_prerelease"`
+	IsUpdated    bool            `json:"is_updated"`
+	Note         *ReleaseNote    `json:"note"`
+	Account      *ReleaseAccount `json:"account"`
+}
+
+// Validate returns an error if any of the fields required to create an issue
+// are empty, as that would result in issues such as "Update  to version ".
+func (r Release) Validate() error {
+	switch {
+	case strings.TrimSpace(r.Project) == "":
+		return errors.New("release project must be set")
+	case strings.TrimSpace(r.Version) == "":
+		return errors.New("release version must be set")
+	}
+	return nil
+}
+
+// sanitized returns the release with control characters, such as newlines
+// and tabs, removed from the provider, project, and version, as those are
+// used in JQL queries, issue summaries, labels, and log lines.
+func (r Release) sanitized() Release {
+	r.Provider = stripControlChars(r.Provider)
+	r.Project = stripControlChars(r.Project)
+	r.Version = stripControlChars(r.Version)
+	return r
+}
+
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// ReleaseNote contains the release notes, such as a changelog,
+// which is not provided on all releases.
+type ReleaseNote struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// ReleaseAccount is the newreleases.io account that the webhook was sent for.
+type ReleaseAccount struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// HasNote returns true if the release has any release notes.
+func (r Release) HasNote() bool {
+	return r.Note != nil && (r.Note.Title != "" || r.Note.Message != "")
+}
+
+// MarshalZerologObject adds the release fields to a log event, e.g:
+//
+//	log.Info().EmbedObject(release).Msg("Received release.")
+func (r Release) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("provider", r.Provider).
+		Str("project", r.Project).
+		Str("version", r.Version)
+}
+
+// Generates a Textual summary for the release, intended to be used as the Jira issue summary.
+// Falls back to a default summary if no template is provided.
+func (r Release) IssueSummary(cfg *config.JiraIssue) (string, error) {
+	summary := fmt.Sprintf("Update %v to version %v", r.Project, r.Version)
+	if cfg.Summary != nil {
+		var err error
+		summary, err = cfg.Summary.Render(r)
+		if err != nil {
+			return "", fmt.Errorf("render issue summary template: %w", err)
+		}
+	}
+	if r.HasCVE() && cfg.Security.SummaryCVEs {
+		summary = fmt.Sprintf("%s (%s)", summary, strings.Join(r.CVE, ", "))
+	}
+	if r.HasCVE() && cfg.Security.SummaryPrefix {
+		summary = "[SECURITY] " + summary
+	}
+	if env := cfg.Environment; env.Tag != "" && env.Mode.PrefixesSummary() {
+		summary = fmt.Sprintf("[%s] %s", env.Tag, summary)
+	}
+	if truncated, ok := truncateSummary(summary, cfg.MaxSummaryLength); ok {
+		log.Warn().
+			EmbedObject(r).
+			Int("length", utf8.RuneCountInString(summary)).
+			Uint("maxLength", cfg.MaxSummaryLength).
+			Msg("Truncated issue summary, as it's too long for Jira.")
+		summary = truncated
+	}
+	return summary, nil
+}
+
+// summarySampleVersion is rendered in place of the version when checking if
+// a summary was generated by jelease. It is a valid version, so that template
+// functions such as semverMajor work, with segments that are unlikely to
+// appear anywhere else in the summary.
+const summarySampleVersion = "9191.8282.7373"
+
+// IsGeneratedSummary returns false if the summary does not match the summary
+// that would be generated for this release with any version, which means
+// that it has been edited manually. The summary is checked both with and
+// without the release's CVEs, as those may differ between releases.
+// Summaries truncated via [config.JiraIssue.MaxSummaryLength] only need to
+// match the beginning of the generated summary.
+func (r Release) IsGeneratedSummary(summary string, cfg *config.JiraIssue) bool {
+	untruncated := *cfg
+	untruncated.MaxSummaryLength = 0
+	runes := []rune(summary)
+	truncated := cfg.MaxSummaryLength > 0 &&
+		uint(len(runes)) == cfg.MaxSummaryLength &&
+		strings.HasSuffix(summary, "…")
+	for _, cves := range [][]string{r.CVE, nil} {
+		candidate := r
+		candidate.Version = summarySampleVersion
+		candidate.CVE = cves
+		rendered, err := candidate.IssueSummary(&untruncated)
+		if err != nil {
+			return false
+		}
+		tokens := summaryTokens(rendered)
+		if matchSummaryTokens(tokens, runes, false) ||
+			truncated && matchSummaryTokens(tokens, runes[:len(runes)-1], true) {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryToken is either a literal part of a generated summary, or a part
+// that depends on the version, which matches one or more runes of a class.
+type summaryToken struct {
+	literal []rune
+	class   func(rune) bool
+}
+
+// summaryTokens splits a summary rendered with the summarySampleVersion into
+// tokens, where the sample version matches any version, and its segments
+// (such as from semverMajor) match any number.
+func summaryTokens(rendered string) []summaryToken {
+	isVersion := func(r rune) bool { return !unicode.IsSpace(r) }
+	var tokens []summaryToken
+	for i, part := range strings.Split(rendered, summarySampleVersion) {
+		if i > 0 {
+			tokens = append(tokens, summaryToken{class: isVersion})
+		}
+		tokens = append(tokens, summarySegmentTokens(part, strings.Split(summarySampleVersion, "."))...)
+	}
+	return tokens
+}
+
+func summarySegmentTokens(part string, segments []string) []summaryToken {
+	if len(segments) == 0 {
+		if part == "" {
+			return nil
+		}
+		return []summaryToken{{literal: []rune(part)}}
+	}
+	var tokens []summaryToken
+	for i, subpart := range strings.Split(part, segments[0]) {
+		if i > 0 {
+			tokens = append(tokens, summaryToken{class: unicode.IsDigit})
+		}
+		tokens = append(tokens, summarySegmentTokens(subpart, segments[1:])...)
+	}
+	return tokens
+}
+
+// matchSummaryTokens returns true if the summary matches the tokens. When
+// partial is set, then the summary only needs to match the beginning of the
+// tokens, as is the case for truncated summaries.
+func matchSummaryTokens(tokens []summaryToken, summary []rune, partial bool) bool {
+	if len(tokens) == 0 {
+		return len(summary) == 0
+	}
+	if partial && len(summary) == 0 {
+		return true
+	}
+	token := tokens[0]
+	if token.class == nil {
+		n := len(token.literal)
+		if len(summary) < n {
+			return partial && slices.Equal(token.literal[:len(summary)], summary)
+		}
+		return slices.Equal(token.literal, summary[:n]) &&
+			matchSummaryTokens(tokens[1:], summary[n:], partial)
+	}
+	for n := 1; n <= len(summary) && token.class(summary[n-1]); n++ {
+		if matchSummaryTokens(tokens[1:], summary[n:], partial) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecurityJiraIssue returns the additional issue to create in the
+// security project, as configured via [config.JiraIssueSecurity.Project].
+// It is created the same way as the regular issue, but not as a subtask,
+// as the parent issue is in another project.
+func (r Release) SecurityJiraIssue(cfg *config.JiraIssue) (jira.Issue, error) {
+	securityCfg := *cfg
+	securityCfg.Project = cfg.Security.Project
+	securityCfg.ProjectMapping = nil
+	securityCfg.ParentKey = ""
+	return r.JiraIssue(&securityCfg)
+}
+
+// truncateSummary shortens the summary to the max length in characters,
+// ending with an ellipsis, and returns false if no truncation was needed.
+// A max length of 0 means no limit.
+func truncateSummary(summary string, maxLength uint) (string, bool) {
+	runes := []rune(summary)
+	if maxLength == 0 || uint(len(runes)) <= maxLength {
+		return summary, false
+	}
+	return string(runes[:maxLength-1]) + "…", true
+}
+
+// TemplateContextDescription is the data available in the issue description
+// template, which is the release with some additional fields.
+type TemplateContextDescription struct {
+	Release
+	ReleaseURL string
+}
+
+// defaultDescription is the issue description template used when none is
+// configured, the same as the default of jira.issue.description.
+var defaultDescription = template.Must(template.New("").Parse(`Acceptance criteria:
+
+* Checked changelogs for breaking changes
+* Updated the software to the specified new version and deployed to all clusters
+{{- with .ReleaseURL }}
+
+Release: [{{ . }}]
+{{- end }}
+
+??Update issue generated by [https://github.com/RiskIdent/jelease].??
+`))
+
+// Generates the Jira issue description for the release.
+// Falls back to the built-in default description if no template is provided.
+func (r Release) IssueDescription(cfg *config.JiraIssue) (string, error) {
+	tmpl := cfg.Description
+	if tmpl == nil {
+		tmpl = (*config.Template)(defaultDescription)
+	}
+	releaseURL, err := r.releaseURL(cfg.ReleaseURLs)
+	if err != nil {
+		return "", err
+	}
+	description, err := tmpl.Render(TemplateContextDescription{
+		Release:    r,
+		ReleaseURL: releaseURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("render issue description template: %w", err)
+	}
+	return description, nil
+}
+
+// releaseURL renders the URL to the release page for the release's provider.
+// Returns an empty string if there is no URL template for the provider.
+func (r Release) releaseURL(urls map[string]*config.Template) (string, error) {
+	tmpl, ok := urls[r.Provider]
+	if !ok || tmpl == nil {
+		return "", nil
+	}
+	url, err := tmpl.Render(r)
+	if err != nil {
+		return "", fmt.Errorf("render release URL template for provider %q: %w", r.Provider, err)
+	}
+	return strings.TrimSpace(url), nil
+}
+
+// HasCVE returns true if the release fixes any known vulnerabilities.
+func (r Release) HasCVE() bool {
+	return len(r.CVE) > 0
+}
+
+func (r Release) issueType(cfg *config.JiraIssue) string {
+	if cfg.ParentKey != "" {
+		return cfg.SubtaskType
+	}
+	if r.HasCVE() && cfg.SecurityType != "" {
+		return cfg.SecurityType
+	}
+	return cfg.TypeForProvider(r.Provider)
 }
 
-// Generates a Textual summary for the release, intended to be used as the Jira issue summary
-func (r Release) IssueSummary() string {
-	return fmt.Sprintf("Update %v to version %v", r.Project, r.Version)
+func (r Release) issuePriority(cfg *config.JiraIssue) string {
+	if r.HasCVE() && cfg.SecurityPriority != "" {
+		return cfg.SecurityPriority
+	}
+	return cfg.Priority
+}
+
+// issueDueDate returns the due date of created issues, relative to now, or
+// the zero time if no due date is configured.
+func (r Release) issueDueDate(cfg *config.JiraIssue, now time.Time) time.Time {
+	days := cfg.DueInDays
+	if r.HasCVE() && cfg.SecurityDueInDays > 0 {
+		days = cfg.SecurityDueInDays
+	}
+	if days == 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, int(days))
+}
+
+// MatchLabel returns the label used to find existing issues to update for
+// this release, depending on the match strategy, e.g "neuvector@5.1" for the
+// "project-minor" strategy. Returns an empty string for the "project"
+// strategy, as then the package label alone is used.
+//
+// Versions that cannot be parsed are matched on the exact version.
+func (r Release) MatchLabel(strategy config.MatchStrategy) string {
+	var key string
+	switch strategy {
+	case config.MatchStrategyProjectMajor, config.MatchStrategyProjectMinor:
+		ver, err := version.Parse(r.Version)
+		if err != nil {
+			key = r.Version
+			break
+		}
+		key = fmt.Sprint(ver.Segment(0))
+		if strategy == config.MatchStrategyProjectMinor {
+			key = fmt.Sprintf("%s.%d", key, ver.Segment(1))
+		}
+	case config.MatchStrategyExactVersion:
+		key = r.Version
+	default:
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '-'
+		}
+		return r
+	}, r.Project+"@"+key)
 }
 
-func (r Release) JiraIssue(cfg *config.JiraIssue) jira.Issue {
+// IssueMarkerLabel returns the rendered marker label used to identify the
+// issue created for this exact release, or an empty string if disabled.
+func (r Release) IssueMarkerLabel(tmpl *config.Template) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+	label, err := tmpl.Render(r)
+	if err != nil {
+		return "", fmt.Errorf("render issue marker label template: %w", err)
+	}
+	return strings.TrimSpace(label), nil
+}
+
+// renderLabel renders the label as a template against the release, such as
+// "provider-{{ .Provider }}". Labels without template actions are returned
+// as-is.
+func (r Release) renderLabel(label string) (string, error) {
+	if !strings.Contains(label, "{{") {
+		return label, nil
+	}
+	var tmpl config.Template
+	if err := tmpl.Set(label); err != nil {
+		return "", fmt.Errorf("parse issue label template %q: %w", label, err)
+	}
+	rendered, err := tmpl.Render(r)
+	if err != nil {
+		return "", fmt.Errorf("render issue label template %q: %w", label, err)
+	}
+	return strings.TrimSpace(rendered), nil
+}
+
+// IssueLabels returns the labels to add to created Jira issues, which is
+// the configured labels plus the configured labels for the release's provider.
+// The package name label is added separately, by the Jira client.
+func (r Release) IssueLabels(cfg *config.JiraIssue) ([]string, error) {
+	var labels []string
+	for _, label := range util.Concat(cfg.Labels, cfg.ProviderLabels[r.Provider]) {
+		rendered, err := r.renderLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.LowercaseLabels {
+			rendered = strings.ToLower(rendered)
+		}
+		labels = append(labels, rendered)
+	}
+	markerLabel, err := r.IssueMarkerLabel(cfg.MarkerLabel)
+	if err != nil {
+		return nil, err
+	}
+	if matchLabel := r.MatchLabel(cfg.MatchStrategy); matchLabel != "" {
+		labels = append(labels, matchLabel)
+	}
+	if cfg.ManagedLabel != "" && !slices.Contains(labels, cfg.ManagedLabel) {
+		labels = append(labels, cfg.ManagedLabel)
+	}
+	if markerLabel != "" {
+		labels = append(labels, markerLabel)
+	}
+	if env := cfg.Environment; env.Tag != "" && env.Mode.AddsLabel() && !slices.Contains(labels, env.Tag) {
+		labels = append(labels, env.Tag)
+	}
+	for _, label := range r.securityLabels(&cfg.Security) {
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	labels = dedupeLabels(labels)
+	for _, label := range labels {
+		if strings.IndexFunc(label, unicode.IsSpace) != -1 {
+			return nil, fmt.Errorf("invalid Jira label %q: labels cannot contain spaces", label)
+		}
+	}
+	return labels, nil
+}
+
+// dedupeLabels trims the labels and removes empty and duplicate labels,
+// while keeping the order. Such as when a templated label renders the same
+// as another label, e.g "{{ .Provider }}" and "github".
+func dedupeLabels(labels []string) []string {
+	deduped := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label != "" && !slices.Contains(deduped, label) {
+			deduped = append(deduped, label)
+		}
+	}
+	return deduped
+}
+
+// securityLabels returns the labels to add for the release's CVEs, such as
+// "security" and "cve-2022-1234".
+func (r Release) securityLabels(cfg *config.JiraIssueSecurity) []string {
+	if !r.HasCVE() {
+		return nil
+	}
+	var labels []string
+	if cfg.Label {
+		labels = append(labels, "security")
+	}
+	if cfg.CVELabels {
+		for _, cve := range r.CVE {
+			label := strings.ToLower(strings.Join(strings.Fields(cve), ""))
+			if !strings.HasPrefix(label, "cve-") {
+				label = "cve-" + label
+			}
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+func (r Release) JiraIssue(cfg *config.JiraIssue) (jira.Issue, error) {
+	summary, err := r.IssueSummary(cfg)
+	if err != nil {
+		return jira.Issue{}, err
+	}
+	description, err := r.IssueDescription(cfg)
+	if err != nil {
+		return jira.Issue{}, err
+	}
+	labels, err := r.IssueLabels(cfg)
+	if err != nil {
+		return jira.Issue{}, err
+	}
+	var fixVersion string
+	if cfg.FixVersion != nil {
+		fixVersion, err = cfg.FixVersion.Render(r)
+		if err != nil {
+			return jira.Issue{}, fmt.Errorf("render issue fix version template: %w", err)
+		}
+	}
+	projectKey := cfg.ProjectForProvider(r.Provider)
 	return jira.Issue{
-		Description:        cfg.Description,
-		ProjectKey:         cfg.Project,
-		TypeName:           cfg.Type,
-		Labels:             cfg.Labels,
-		Summary:            r.IssueSummary(),
+		Description:        description,
+		ProjectKey:         projectKey,
+		TypeName:           r.issueType(cfg),
+		Priority:           r.issuePriority(cfg),
+		Assignee:           cfg.Assignee,
+		Reporter:           cfg.Reporter,
+		SecurityLevel:      cfg.SecurityLevel,
+		FixVersion:         strings.TrimSpace(fixVersion),
+		Components:         cfg.ComponentsForProject(projectKey),
+		DueDate:            r.issueDueDate(cfg, time.Now()),
+		ParentKey:          cfg.ParentKey,
+		Labels:             labels,
+		Summary:            summary,
 		PackageName:        r.Project,
 		PackageNameFieldID: cfg.ProjectNameCustomField,
-	}
+		CustomFields:       cfg.CustomFields,
+	}, nil
 }
@@ -0,0 +1,578 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/version"
+	"golang.org/x/exp/slices"
+)
+
+func TestReleaseIssueLabels(t *testing.T) {
+	cfg := config.JiraIssue{
+		Labels: []string{"jelease", "update"},
+		ProviderLabels: map[string][]string{
+			"npm":  {"npm", "frontend"},
+			"pypi": {"pypi"},
+		},
+	}
+
+	tests := []struct {
+		provider string
+		want     []string
+	}{
+		{provider: "npm", want: []string{"jelease", "update", "npm", "frontend"}},
+		{provider: "pypi", want: []string{"jelease", "update", "pypi"}},
+		{provider: "github", want: []string{"jelease", "update"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.provider, func(t *testing.T) {
+			got, err := Release{Provider: tc.provider}.IssueLabels(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_managedLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   []string
+	}{
+		{name: "appended", labels: []string{"update"}, want: []string{"update", "jelease"}},
+		{name: "not duplicated", labels: []string{"jelease", "update"}, want: []string{"jelease", "update"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.JiraIssue{Labels: tc.labels, ManagedLabel: "jelease"}
+			got, err := Release{}.IssueLabels(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_spaces(t *testing.T) {
+	cfg := config.JiraIssue{
+		ProviderLabels: map[string][]string{
+			"npm": {"node js"},
+		},
+	}
+	_, err := Release{Provider: "npm"}.IssueLabels(&cfg)
+	if err == nil {
+		t.Error("want error, got nil")
+	}
+}
+
+func TestReleaseIssueLabels_templated(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "static", labels: []string{"jelease"}, want: []string{"jelease"}},
+		{name: "templated", labels: []string{"jelease", "provider-{{ .Provider }}"}, want: []string{"jelease", "provider-npm"}},
+		{name: "pipeline", labels: []string{`{{ .Version | printf "v%s" }}`}, want: []string{"v1.3.0"}},
+		{name: "rendered with spaces", labels: []string{"{{ .Project }} {{ .Version }}"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.JiraIssue{Labels: tc.labels}
+			got, err := Release{Provider: "npm", Project: "left-pad", Version: "1.3.0"}.IssueLabels(&cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_deduped(t *testing.T) {
+	tests := []struct {
+		name      string
+		lowercase bool
+		want      []string
+	}{
+		{name: "keep case", want: []string{"jelease", "Neuvector", "neuvector", "provider-GitHub"}},
+		{name: "lowercase", lowercase: true, want: []string{"jelease", "neuvector", "provider-github"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.JiraIssue{
+				Labels:          []string{"jelease", "{{ .Project }}", "neuvector", " ", "{{ if .IsPrerelease }}prerelease{{ end }}"},
+				LowercaseLabels: tc.lowercase,
+				ProviderLabels: map[string][]string{
+					"GitHub": {"provider-{{ .Provider }}", "jelease", "{{ .Project }}"},
+				},
+			}
+			got, err := Release{Provider: "GitHub", Project: "Neuvector"}.IssueLabels(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_markerLabel(t *testing.T) {
+	var markerLabel config.Template
+	if err := markerLabel.Set(`jelease-{{ .Project }}-{{ .Version }}`); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.JiraIssue{
+		Labels:      []string{"jelease"},
+		MarkerLabel: &markerLabel,
+	}
+	got, err := Release{Project: "neuvector", Version: "v5.1.3"}.IssueLabels(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"jelease", "jelease-neuvector-v5.1.3"}
+	if !slices.Equal(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReleaseMatchLabel(t *testing.T) {
+	tests := []struct {
+		strategy config.MatchStrategy
+		version  string
+		want     string
+	}{
+		{strategy: config.MatchStrategyProject, version: "v5.1.3", want: ""},
+		{strategy: config.MatchStrategyProjectMajor, version: "v5.1.3", want: "neuvector@5"},
+		{strategy: config.MatchStrategyProjectMinor, version: "v5.1.3", want: "neuvector@5.1"},
+		{strategy: config.MatchStrategyProjectMinor, version: "5", want: "neuvector@5.0"},
+		{strategy: config.MatchStrategyProjectMinor, version: "latest build", want: "neuvector@latest-build"},
+		{strategy: config.MatchStrategyExactVersion, version: "v5.1.3", want: "neuvector@v5.1.3"},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.strategy)+"/"+tc.version, func(t *testing.T) {
+			got := Release{Project: "neuvector", Version: tc.version}.MatchLabel(tc.strategy)
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueDescription_releaseURL(t *testing.T) {
+	var description, npmURL config.Template
+	if err := description.Set(`{{ .Project }}{{ with .ReleaseURL }}: {{ . }}{{ end }}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := npmURL.Set(`https://www.npmjs.com/package/{{ .Project }}/v/{{ .Version }}`); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.JiraIssue{
+		Description: &description,
+		ReleaseURLs: map[string]*config.Template{"npm": &npmURL},
+	}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{provider: "npm", want: "left-pad: https://www.npmjs.com/package/left-pad/v/1.3.0"},
+		{provider: "cargo", want: "left-pad"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.provider, func(t *testing.T) {
+			got, err := Release{Provider: tc.provider, Project: "left-pad", Version: "1.3.0"}.IssueDescription(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueDescription_default(t *testing.T) {
+	got, err := Release{Provider: "npm", Project: "left-pad", Version: "1.3.0"}.IssueDescription(&config.JiraIssue{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Acceptance criteria:
+
+* Checked changelogs for breaking changes
+* Updated the software to the specified new version and deployed to all clusters
+
+??Update issue generated by [https://github.com/RiskIdent/jelease].??
+`
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestReleaseIssueType(t *testing.T) {
+	cfg := config.JiraIssue{
+		Type:          "Task",
+		ProviderTypes: map[string]string{"docker": "Story"},
+		SecurityType:  "Bug",
+	}
+	tests := []struct {
+		name    string
+		release Release
+		want    string
+	}{
+		{name: "default", release: Release{Provider: "npm"}, want: "Task"},
+		{name: "provider", release: Release{Provider: "docker"}, want: "Story"},
+		{name: "security", release: Release{Provider: "docker", CVE: []string{"CVE-2022-1234"}}, want: "Bug"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.release.issueType(&cfg); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseJiraIssue_parentKey(t *testing.T) {
+	cfg := config.JiraIssue{
+		Type:         "Task",
+		SecurityType: "Bug",
+		ParentKey:    "OP-123",
+		SubtaskType:  "Sub-task",
+	}
+	got, err := Release{Project: "neuvector", Version: "v5.1.3", CVE: []string{"CVE-2022-1234"}}.JiraIssue(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentKey != "OP-123" {
+		t.Errorf("want parent %q, got %q", "OP-123", got.ParentKey)
+	}
+	if got.TypeName != "Sub-task" {
+		t.Errorf("want type %q, got %q", "Sub-task", got.TypeName)
+	}
+}
+
+func TestReleaseIssueSummary_environment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  config.JiraIssueEnvironment
+		want string
+	}{
+		{name: "no tag", env: config.JiraIssueEnvironment{Mode: config.EnvironmentModeBoth}, want: "Update neuvector to version v5.1.3"},
+		{name: "summary", env: config.JiraIssueEnvironment{Tag: "staging", Mode: config.EnvironmentModeSummary}, want: "[staging] Update neuvector to version v5.1.3"},
+		{name: "label only", env: config.JiraIssueEnvironment{Tag: "staging", Mode: config.EnvironmentModeLabel}, want: "Update neuvector to version v5.1.3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.JiraIssue{Environment: tc.env}
+			got, err := Release{Project: "neuvector", Version: "v5.1.3"}.IssueSummary(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_environment(t *testing.T) {
+	cfg := config.JiraIssue{
+		Labels:      []string{"jelease"},
+		Environment: config.JiraIssueEnvironment{Tag: "staging", Mode: config.EnvironmentModeLabel},
+	}
+	got, err := Release{}.IssueLabels(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"jelease", "staging"}
+	if !slices.Equal(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReleaseIssueDueDate(t *testing.T) {
+	now := time.Date(2023, 3, 30, 12, 0, 0, 0, time.UTC)
+	cfg := config.JiraIssue{DueInDays: 14, SecurityDueInDays: 3}
+	tests := []struct {
+		name    string
+		cfg     config.JiraIssue
+		release Release
+		want    string
+	}{
+		{name: "unset", release: Release{}, want: ""},
+		{name: "regular", cfg: cfg, release: Release{}, want: "2023-04-13"},
+		{name: "security", cfg: cfg, release: Release{CVE: []string{"CVE-2022-1234"}}, want: "2023-04-02"},
+		{name: "security fallback", cfg: config.JiraIssue{DueInDays: 14}, release: Release{CVE: []string{"CVE-2022-1234"}}, want: "2023-04-13"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			if due := tc.release.issueDueDate(&tc.cfg, now); !due.IsZero() {
+				got = due.Format("2006-01-02")
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueSummary_security(t *testing.T) {
+	cfg := config.JiraIssue{
+		Security: config.JiraIssueSecurity{SummaryPrefix: true, SummaryCVEs: true},
+	}
+	tests := []struct {
+		name string
+		cve  []string
+		want string
+	}{
+		{name: "no CVE", want: "Update neuvector to version v5.1.3"},
+		{name: "single CVE", cve: []string{"CVE-2022-1234"}, want: "[SECURITY] Update neuvector to version v5.1.3 (CVE-2022-1234)"},
+		{name: "multiple CVEs", cve: []string{"CVE-2022-1234", "CVE-2022-5678"}, want: "[SECURITY] Update neuvector to version v5.1.3 (CVE-2022-1234, CVE-2022-5678)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Release{Project: "neuvector", Version: "v5.1.3", CVE: tc.cve}.IssueSummary(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueLabels_security(t *testing.T) {
+	cfg := config.JiraIssue{
+		Labels:   []string{"jelease"},
+		Security: config.JiraIssueSecurity{Label: true, CVELabels: true},
+	}
+	tests := []struct {
+		name string
+		cve  []string
+		want []string
+	}{
+		{name: "no CVE", want: []string{"jelease"}},
+		{name: "single CVE", cve: []string{"CVE-2022-1234"}, want: []string{"jelease", "security", "cve-2022-1234"}},
+		{name: "multiple CVEs", cve: []string{"CVE-2022-1234", "CVE-2022-5678"}, want: []string{"jelease", "security", "cve-2022-1234", "cve-2022-5678"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Release{CVE: tc.cve}.IssueLabels(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseSanitized(t *testing.T) {
+	tests := []struct {
+		name    string
+		release Release
+		want    Release
+	}{
+		{
+			name:    "clean",
+			release: Release{Provider: "npm", Project: "@scope/left-pad", Version: "1.3.0"},
+			want:    Release{Provider: "npm", Project: "@scope/left-pad", Version: "1.3.0"},
+		},
+		{
+			name:    "newlines and tabs",
+			release: Release{Provider: "np\tm", Project: "left-pad\n\" or labels = \"x", Version: "1.3.0\r\n"},
+			want:    Release{Provider: "npm", Project: "left-pad\" or labels = \"x", Version: "1.3.0"},
+		},
+		{
+			name:    "other control characters",
+			release: Release{Project: "left\x00-pad\x1b[31m", Version: "1.3.0\u0085"},
+			want:    Release{Project: "left-pad[31m", Version: "1.3.0"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.release.sanitized()
+			if got.Provider != tc.want.Provider || got.Project != tc.want.Project || got.Version != tc.want.Version {
+				t.Errorf("want %q %q %q, got %q %q %q",
+					tc.want.Provider, tc.want.Project, tc.want.Version,
+					got.Provider, got.Project, got.Version)
+			}
+		})
+	}
+}
+
+func TestReleaseIssueSummary_maxLength(t *testing.T) {
+	release := Release{Project: strings.Repeat("a", 300), Version: "v1.2.3"}
+	cfg := config.JiraIssue{MaxSummaryLength: 255}
+	got, err := release.IssueSummary(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(got); n != 255 {
+		t.Errorf("want summary of 255 characters, got %d", n)
+	}
+	if !strings.HasPrefix(got, "Update aaa") || !strings.HasSuffix(got, "a…") {
+		t.Errorf("want truncated summary ending with ellipsis, got %q", got)
+	}
+
+	cfg.MaxSummaryLength = 0
+	got, err = release.IssueSummary(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Update " + release.Project + " to version v1.2.3"; got != want {
+		t.Errorf("want untruncated summary without limit, got %q", got)
+	}
+}
+
+func TestReleaseIsGeneratedSummary(t *testing.T) {
+	var tmpl config.Template
+	tmpl.Set("Update {{ .Project }} to {{ .Version }}")
+	cfg := config.JiraIssue{
+		Summary:  &tmpl,
+		Security: config.JiraIssueSecurity{SummaryCVEs: true},
+	}
+	tests := []struct {
+		name    string
+		summary string
+		cve     []string
+		want    bool
+	}{
+		{name: "older version", summary: "Update neuvector to v5.1.2", want: true},
+		{name: "other version format", summary: "Update neuvector to 5.1.2-rc.1", want: true},
+		{name: "older release with CVE", summary: "Update neuvector to v5.1.2 (CVE-2022-1234)", cve: []string{"CVE-2022-1234"}, want: true},
+		{name: "older release without CVE", summary: "Update neuvector to v5.1.2", cve: []string{"CVE-2022-1234"}, want: true},
+		{name: "edited", summary: "Update neuvector to v5.1.2, blocked by OP-42", want: false},
+		{name: "edited prefix", summary: "URGENT: Update neuvector to v5.1.2", want: false},
+		{name: "other project", summary: "Update nginx to v5.1.2", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Release{Project: "neuvector", Version: "v5.1.3", CVE: tc.cve}
+			if got := r.IsGeneratedSummary(tc.summary, &cfg); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIsGeneratedSummary_semver(t *testing.T) {
+	config.FuncsMap = map[string]any{
+		"semverMajor": func(value string) uint {
+			ver, err := version.Parse(value)
+			if err != nil {
+				panic(err)
+			}
+			return ver.Segment(0)
+		},
+	}
+	defer func() { config.FuncsMap = nil }()
+	var tmpl config.Template
+	if err := tmpl.Set("Update {{ .Project }} to v{{ semverMajor .Version }}.x ({{ .Version }})"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.JiraIssue{Summary: &tmpl}
+	tests := []struct {
+		name    string
+		summary string
+		want    bool
+	}{
+		{name: "generated", summary: "Update neuvector to v5.x (v5.1.2)", want: true},
+		{name: "edited", summary: "Update neuvector to v5.x (v5.1.2) after OP-42", want: false},
+		{name: "not a number", summary: "Update neuvector to vFive.x (v5.1.2)", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Release{Project: "neuvector", Version: "v5.1.3"}
+			if got := r.IsGeneratedSummary(tc.summary, &cfg); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseIsGeneratedSummary_truncated(t *testing.T) {
+	project := strings.Repeat("a", 40)
+	cfg := config.JiraIssue{MaxSummaryLength: 50}
+	tests := []struct {
+		name    string
+		summary string
+		want    bool
+	}{
+		// "Update <project> to version v5.1.2" truncated to 50 characters
+		{name: "truncated", summary: "Update " + project + " t…", want: true},
+		{name: "truncated edited", summary: "Update " + project + " X…", want: false},
+		{name: "too short to be truncated", summary: "Update " + project + "…", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Release{Project: project, Version: "v5.1.3"}
+			if got := r.IsGeneratedSummary(tc.summary, &cfg); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+
+	cfg.MaxSummaryLength = 40
+	r := Release{Project: "neuvector", Version: "v5.1.3"}
+	// "Update neuvector to version v5.1.2.12345" truncated within the version
+	if summary := "Update neuvector to version v5.1.2.1234…"; !r.IsGeneratedSummary(summary, &cfg) {
+		t.Errorf("want summary truncated within the version to be generated: %q", summary)
+	}
+}
+
+func TestTruncateSummary(t *testing.T) {
+	tests := []struct {
+		summary   string
+		maxLength uint
+		want      string
+		wantOK    bool
+	}{
+		{summary: "Update foo", maxLength: 10, want: "Update foo"},
+		{summary: "Update foo", maxLength: 8, want: "Update …", wantOK: true},
+		{summary: "Update föö", maxLength: 9, want: "Update f…", wantOK: true},
+		{summary: "Update foo", maxLength: 0, want: "Update foo"},
+	}
+	for _, tc := range tests {
+		got, ok := truncateSummary(tc.summary, tc.maxLength)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("truncateSummary(%q, %d): want %q %t, got %q %t", tc.summary, tc.maxLength, tc.want, tc.wantOK, got, ok)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+const payloadFilePrefix = "payload-"
+
+// payloadStore writes raw webhook payloads to files in a directory, while
+// capping the number and size of the stored files to not fill up the disk.
+type payloadStore struct {
+	mu          sync.Mutex
+	dir         string
+	maxFiles    int
+	maxFileSize int
+}
+
+// newPayloadStore returns nil if no directory is configured.
+func newPayloadStore(cfg config.HTTPFailedPayloads) *payloadStore {
+	if cfg.Dir == "" {
+		return nil
+	}
+	return &payloadStore{
+		dir:         cfg.Dir,
+		maxFiles:    int(cfg.MaxFiles),
+		maxFileSize: int(cfg.MaxFileSize),
+	}
+}
+
+// save writes the payload to a new file named after the timestamp, and
+// deletes the oldest files if there are more than maxFiles. Returns the path
+// of the written file. A nil *payloadStore does nothing.
+func (s *payloadStore) save(body []byte, now time.Time) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	if s.maxFileSize > 0 && len(body) > s.maxFileSize {
+		return "", fmt.Errorf("payload of %d bytes exceeds the max file size of %d bytes", len(body), s.maxFileSize)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("create payload dir: %w", err)
+	}
+	pattern := payloadFilePrefix + now.UTC().Format("20060102T150405.000000000Z") + "-*.json"
+	f, err := os.CreateTemp(s.dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("create payload file: %w", err)
+	}
+	_, err = f.Write(body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write payload file: %w", err)
+	}
+	if err := s.prune(); err != nil {
+		log.Warn().Err(err).Str("dir", s.dir).Msg("Failed to delete old payload files.")
+	}
+	return f.Name(), nil
+}
+
+// prune deletes the oldest payload files exceeding maxFiles. Only files
+// written by the store are considered, so other files in the directory are
+// left alone.
+func (s *payloadStore) prune() error {
+	if s.maxFiles <= 0 {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(s.dir, payloadFilePrefix+"*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) <= s.maxFiles {
+		return nil
+	}
+	// the timestamp in the file names makes them sort from oldest to newest
+	sort.Strings(files)
+	for _, file := range files[:len(files)-s.maxFiles] {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeFailedPayload stores a webhook payload that failed to decode, if
+// enabled via [config.HTTP.FailedPayloads].
+func (s HTTPServer) storeFailedPayload(body []byte) {
+	path, err := s.payloads.save(body, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to store webhook payload.")
+		return
+	}
+	if path != "" {
+		log.Info().Str("file", path).Msg("Stored webhook payload that failed to decode.")
+	}
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+)
+
+func TestPayloadStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "payloads")
+	store := newPayloadStore(config.HTTPFailedPayloads{Dir: dir, MaxFiles: 2})
+	start := time.Date(2023, 3, 17, 15, 4, 5, 0, time.UTC)
+
+	var paths []string
+	for i, body := range []string{"first", "second", "third"} {
+		path, err := store.save([]byte(body), start.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Errorf("want oldest payload to be deleted, got %v", err)
+	}
+	for i, want := range []string{"second", "third"} {
+		got, err := os.ReadFile(paths[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("want payload %q, got %q", want, got)
+		}
+	}
+}
+
+func TestPayloadStore_keepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store := newPayloadStore(config.HTTPFailedPayloads{Dir: dir, MaxFiles: 1})
+	for i := 0; i < 2; i++ {
+		if _, err := store.save([]byte("{}"), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("want unrelated file to be kept, got %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "payload-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("want 1 payload file, got %v", files)
+	}
+}
+
+func TestPayloadStore_maxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	store := newPayloadStore(config.HTTPFailedPayloads{Dir: dir, MaxFileSize: 4})
+	if _, err := store.save([]byte("too large"), time.Now()); err == nil {
+		t.Error("want error for payload exceeding max file size")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("want no stored payloads, got %d", len(entries))
+	}
+}
+
+func TestPayloadStore_disabled(t *testing.T) {
+	store := newPayloadStore(config.HTTPFailedPayloads{})
+	path, err := store.save([]byte("{}"), time.Now())
+	if err != nil || path != "" {
+		t.Errorf("want disabled store to do nothing, got %q, %v", path, err)
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// releaseQueue holds releases received via webhooks that are waiting to be
+// processed by the worker goroutines.
+type releaseQueue struct {
+	releases chan Release
+	workers  uint
+	wg       *sync.WaitGroup
+}
+
+func newReleaseQueue(size, workers uint) *releaseQueue {
+	if workers == 0 {
+		workers = 1
+	}
+	return &releaseQueue{
+		releases: make(chan Release, size),
+		workers:  workers,
+		wg:       &sync.WaitGroup{},
+	}
+}
+
+// enqueueReleases adds the releases to the queue and responds with
+// 202 Accepted, or with 503 Service Unavailable if the queue is full so that
+// newreleases.io retries the webhook later.
+func (s HTTPServer) enqueueReleases(c *gin.Context, releases []Release) {
+	var dropped int
+	for _, release := range releases {
+		if !s.queue.tryEnqueue(release) {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		c.Header("Retry-After", "10")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "queue is full"})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// tryEnqueue adds the release to the queue without blocking, and returns
+// false if the release was dropped as the queue is full.
+func (q *releaseQueue) tryEnqueue(release Release) bool {
+	select {
+	case q.releases <- release:
+		log.Debug().EmbedObject(release).Msg("Queued release.")
+		return true
+	default:
+		log.Warn().EmbedObject(release).Msg("Dropped release, as the queue is full.")
+		return false
+	}
+}
+
+// startWorkers starts the goroutines that process the queued releases,
+// until the queue is closed.
+func (s HTTPServer) startWorkers() {
+	for i := uint(0); i < s.queue.workers; i++ {
+		s.queue.wg.Add(1)
+		go func() {
+			defer s.queue.wg.Done()
+			for release := range s.queue.releases {
+				ctx, cancel := s.jiraContext(context.Background())
+				// Failures are logged by processReleases, as they cannot be
+				// returned to newreleases.io anymore.
+				results := s.processReleases(ctx, []Release{release})
+				cancel()
+				s.startFollowUps(results)
+			}
+		}()
+	}
+	log.Info().Uint("workers", s.queue.workers).Msg("Started queue workers.")
+}
+
+// drainQueue stops accepting new releases, and waits for the workers to
+// finish processing the releases already in the queue.
+func (s HTTPServer) drainQueue(ctx context.Context) error {
+	log.Info().Int("queued", len(s.queue.releases)).Msg("Draining queue.")
+	close(s.queue.releases)
+	done := make(chan struct{})
+	go func() {
+		s.queue.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnqueueReleases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := HTTPServer{queue: newReleaseQueue(1, 1)}
+
+	tests := []struct {
+		name     string
+		releases []Release
+		want     int
+	}{
+		{
+			name:     "fits",
+			releases: []Release{{Project: "neuvector", Version: "v5.1.3"}},
+			want:     http.StatusAccepted,
+		},
+		{
+			name:     "full",
+			releases: []Release{{Project: "redis", Version: "7.0.8"}},
+			want:     http.StatusServiceUnavailable,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			s.enqueueReleases(c, tc.releases)
+			c.Writer.WriteHeaderNow()
+			if w.Code != tc.want {
+				t.Errorf("want status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+
+	if got := len(s.queue.releases); got != 1 {
+		t.Errorf("want 1 queued release, got %d", got)
+	}
+	if err := s.drainQueue(context.Background()); err != nil {
+		t.Errorf("drain queue: %v", err)
+	}
+}
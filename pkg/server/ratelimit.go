@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/RiskIdent/jelease/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitMiddleware rejects requests with 429 Too Many Requests when
+// exceeding the limiter's rate, including a Retry-After header with the
+// number of seconds until the next request would be allowed.
+func rateLimitMiddleware(limiter *rate.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reservation := limiter.Reserve()
+		if !reservation.OK() {
+			rejectRateLimited(c, 1)
+			return
+		}
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			rejectRateLimited(c, int(math.Ceil(delay.Seconds())))
+			return
+		}
+		c.Next()
+	}
+}
+
+func rejectRateLimited(c *gin.Context, retryAfterSeconds int) {
+	log.Warn().
+		Str("remoteAddr", clientIP(c)).
+		Int("retryAfter", retryAfterSeconds).
+		Msg("Rejected webhook request, as rate limit was exceeded.")
+	metrics.IncWebhook(metrics.WebhookResultRateLimited)
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+}
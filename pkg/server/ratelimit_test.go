@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/", rateLimitMiddleware(rate.NewLimiter(0.1, 2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	wantCodes := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, want := range wantCodes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+		if w.Code != want {
+			t.Errorf("request %d: want status %d, got %d", i+1, want, w.Code)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "10" {
+			t.Errorf("request %d: want Retry-After 10, got %q", i+1, w.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -18,51 +18,157 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/github"
 	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/RiskIdent/jelease/pkg/metrics"
 	"github.com/RiskIdent/jelease/pkg/patch"
+	"github.com/RiskIdent/jelease/pkg/slack"
+	"github.com/RiskIdent/jelease/pkg/version"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
 )
 
+const readinessTimeout = 5 * time.Second
+
 type HTTPServer struct {
-	engine *gin.Engine
-	cfg    *config.Config
-	jira   jira.Client
+	engine    *gin.Engine
+	cfg       *config.Config
+	buildInfo BuildInfo
+	jira      jira.Client
+	namedJira map[string]jira.Client
+	queue     *releaseQueue
+	debouncer *releaseDebouncer
+	locks     *projectLocks
+	stats     *serverStats
+	payloads  *payloadStore
+}
+
+// BuildInfo describes the running binary, as served on the /version endpoint.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
 }
 
-func New(cfg *config.Config, jira jira.Client) *HTTPServer {
+// New creates a new HTTP server. The named Jira clients are the additional
+// Jira connections, as configured via [config.Jira.Connections].
+func New(cfg *config.Config, buildInfo BuildInfo, jira jira.Client, namedJira map[string]jira.Client) *HTTPServer {
 	gin.DefaultErrorWriter = log.Logger
 	gin.DefaultWriter = log.Logger
 
 	r := gin.New()
 
 	r.Use(
-		gin.LoggerWithConfig(gin.LoggerConfig{
-			SkipPaths: []string{"/"},
-		}),
+		clientIPMiddleware(cfg.HTTP.TrustProxy),
+		requestLogMiddleware(log.Logger, cfg.HTTP.HealthPath, "/readyz", "/status", "/metrics"),
 		gin.Recovery(),
 	)
 
 	s := &HTTPServer{
-		engine: r,
-		cfg:    cfg,
-		jira:   jira,
+		engine:    r,
+		cfg:       cfg,
+		buildInfo: buildInfo,
+		jira:      jira,
+		namedJira: namedJira,
+		locks:     newProjectLocks(),
+		stats:     newServerStats(),
+		payloads:  newPayloadStore(cfg.HTTP.FailedPayloads),
+	}
+	if cfg.HTTP.Queue.Enabled {
+		s.queue = newReleaseQueue(cfg.HTTP.Queue.Size, cfg.HTTP.Queue.Workers)
+	}
+	if delay := cfg.HTTP.Debounce.Duration(); delay > 0 {
+		s.debouncer = newReleaseDebouncer(delay, s.processDebouncedRelease)
 	}
 
-	r.GET("/", s.handleGetRoot)
-	r.POST("/webhook", s.handlePostWebhook)
+	r.GET(cfg.HTTP.HealthPath, s.handleGetRoot)
+	r.GET("/readyz", s.handleGetReadiness)
+	r.GET("/version", s.handleGetVersion)
+	r.GET("/status", s.handleGetStatus)
+	r.POST(cfg.HTTP.WebhookPath, s.webhookHandlers()...)
+	r.GET("/issues", s.issuesHandlers()...)
+	if cfg.HTTP.Trigger.Enabled {
+		r.POST("/trigger", bearerTokenMiddleware(cfg.HTTP.Trigger.Token), s.handlePostTrigger)
+	}
+
+	if cfg.HTTP.Metrics.Enabled {
+		r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
 
 	return s
 }
 
+// Serve starts the HTTP server and blocks until it's closed. The server is
+// gracefully shut down on SIGINT or SIGTERM, after which this function
+// returns [http.ErrServerClosed].
 func (s HTTPServer) Serve() error {
-	log.Info().Uint16("port", s.cfg.HTTP.Port).Msg("Starting server.")
-	return s.engine.Run(fmt.Sprintf(":%v", s.cfg.HTTP.Port))
+	timeouts := s.cfg.HTTP.Timeouts
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%v", s.cfg.HTTP.Port),
+		Handler:           s.engine,
+		ReadHeaderTimeout: timeouts.ReadHeader.Duration(),
+		ReadTimeout:       timeouts.Read.Duration(),
+		WriteTimeout:      timeouts.Write.Duration(),
+		IdleTimeout:       timeouts.Idle.Duration(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if s.queue != nil {
+		s.startWorkers()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info().Uint16("port", s.cfg.HTTP.Port).Msg("Starting server.")
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	stop() // restore default signal handling, so a 2nd Ctrl+C kills the process
+
+	timeout := s.cfg.HTTP.ShutdownTimeout.Duration()
+	log.Info().Dur("timeout", timeout).Msg("Shutting down server.")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown server: %w", err)
+	}
+	if s.debouncer != nil {
+		if err := s.debouncer.flush(shutdownCtx); err != nil {
+			return fmt.Errorf("flush debounced releases: %w", err)
+		}
+	}
+	if s.queue != nil {
+		if err := s.drainQueue(shutdownCtx); err != nil {
+			return fmt.Errorf("drain queue: %w", err)
+		}
+	}
+	return <-errCh
 }
 
 // handleGetRoot handles to GET requests for a basic reachability check
@@ -70,28 +176,358 @@ func (HTTPServer) handleGetRoot(c *gin.Context) {
 	c.Data(http.StatusOK, "text/plain", []byte("OK"))
 }
 
+// handleGetVersion handles GET requests for the version of the running binary
+func (s HTTPServer) handleGetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, s.buildInfo)
+}
+
+// handleGetReadiness handles GET requests for a readiness check,
+// which also checks that Jira is reachable.
+func (s HTTPServer) handleGetReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+	if err := s.jira.Ping(ctx); err != nil {
+		log.Warn().Err(err).Msg("Readiness check failed, Jira is unreachable.")
+		c.Data(http.StatusServiceUnavailable, "text/plain", []byte("Jira unreachable"))
+		return
+	}
+	for name, j := range s.namedJira {
+		if err := j.Ping(ctx); err != nil {
+			log.Warn().Err(err).Str("connection", name).Msg("Readiness check failed, Jira is unreachable.")
+			c.Data(http.StatusServiceUnavailable, "text/plain", []byte("Jira unreachable"))
+			return
+		}
+	}
+	c.Data(http.StatusOK, "text/plain", []byte("OK"))
+}
+
 // handlePostWebhook handles newreleases.io webhook post requests
 func (s HTTPServer) handlePostWebhook(c *gin.Context) {
-	// parse newreleases.io webhook
-	var release Release
-	if err := c.ShouldBindJSON(&release); err != nil {
+	body, err := c.GetRawData()
+	if err != nil {
+		metrics.IncWebhook(metrics.WebhookResultBadRequest)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	issueRef, err := ensureJiraIssue(s.jira, release, s.cfg)
+	// parse newreleases.io webhook
+	releases, isBatch, err := decodeReleases(body, s.cfg.HTTP.StrictPayload)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Warn().Err(err).Str("remoteAddr", clientIP(c)).Msg("Failed to decode webhook payload.")
+		s.storeFailedPayload(body)
+		metrics.IncWebhook(metrics.WebhookResultBadRequest)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	go tryApplyChanges(s.jira, release, issueRef.IssueRef, s.cfg)
+	for i, release := range releases {
+		if err := release.Validate(); err != nil {
+			if isBatch {
+				err = fmt.Errorf("release at index %d: %w", i, err)
+			}
+			log.Warn().Err(err).
+				Str("remoteAddr", clientIP(c)).
+				Bytes("payload", body).
+				Msg("Rejected webhook payload with missing fields.")
+			metrics.IncWebhook(metrics.WebhookResultBadRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		releases[i] = s.canonicalRelease(release)
+	}
+
+	log.Info().
+		Str("remoteAddr", clientIP(c)).
+		Int("releases", len(releases)).
+		Msg("Received webhook.")
+
+	if s.cfg.HTTP.Validate.Enabled && c.Query("validate") == "true" {
+		s.respondValidatedIssues(c, releases, isBatch)
+		return
+	}
+	s.stats.webhookReceived()
+
+	if s.debouncer != nil {
+		for _, release := range releases {
+			s.debouncer.add(release)
+		}
+		c.Status(http.StatusAccepted)
+		return
+	}
+	if s.queue != nil {
+		s.enqueueReleases(c, releases)
+		return
+	}
+
+	ctx, cancel := s.jiraContext(c.Request.Context())
+	defer cancel()
+	results := s.processReleases(ctx, releases)
+	s.startFollowUps(results)
+
+	if !isBatch {
+		if err := results[0].err; err != nil {
+			writeJiraError(ctx, c, err, gin.H{"error": err.Error()})
+			return
+		}
+		// NOTE: always return OK, otherwise newreleases.io will retry
+		c.Status(http.StatusOK)
+		return
+	}
 
-	// NOTE: always return OK, otherwise newreleases.io will retry
+	var errs []string
+	var firstErr error
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %s", res.release.Project, res.release.Version, res.err))
+			if firstErr == nil || isRateLimited(res.err) {
+				firstErr = res.err
+			}
+		}
+	}
+	log.Info().
+		Int("total", len(results)).
+		Int("failed", len(errs)).
+		Msg("Processed batch of releases.")
+	if len(errs) > 0 && !s.cfg.HTTP.AllowPartialBatch {
+		writeJiraError(ctx, c, firstErr, gin.H{"errors": errs})
+		return
+	}
 	c.Status(http.StatusOK)
 }
 
-func tryApplyChanges(j jira.Client, release Release, issueRef jira.IssueRef, cfg *config.Config) {
+// canonicalRelease returns the release with the canonical project name,
+// in case the project has been renamed upstream.
+func (s HTTPServer) canonicalRelease(release Release) Release {
+	canonical := s.cfg.CanonicalProject(release.Project)
+	if canonical != release.Project {
+		log.Debug().
+			Str("project", release.Project).
+			Str("canonical", canonical).
+			Msg("Using canonical name of aliased project.")
+		release.Project = canonical
+	}
+	return release
+}
+
+// startFollowUps starts the background tasks for the successfully processed
+// releases, such as creating PRs and sending notifications.
+func (s HTTPServer) startFollowUps(results []releaseResult) {
+	for _, res := range results {
+		if res.err == nil && res.issue.needsFollowUps() {
+			go s.tryApplyChanges(res.release, res.issue.IssueRef)
+			go s.trySendSlackNotification(res.release, res.issue)
+		}
+	}
+}
+
+// findVersionInSummary returns the last word in the issue summary that can
+// be parsed as a version with at least two segments, such as "v5.1.3" in
+// "Update neuvector to v5.1.3".
+func findVersionInSummary(summary string) (version.Version, bool) {
+	words := strings.Fields(summary)
+	for i := len(words) - 1; i >= 0; i-- {
+		word := strings.Trim(words[i], `()[]{}"',:;`)
+		if !strings.Contains(word, ".") {
+			continue
+		}
+		if v, err := version.Parse(word); err == nil {
+			return v, true
+		}
+	}
+	return version.Version{}, false
+}
+
+// jiraContext returns a context for the Jira requests made when handling
+// a webhook, with the configured Jira timeout.
+func (s HTTPServer) jiraContext(parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := s.cfg.Jira.Timeout.Duration()
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// errorStatusCode returns 429 Too Many Requests if Jira is still rate
+// limiting after the retries, also when timing out while waiting to retry,
+// 504 Gateway Timeout if the Jira requests timed out, and 500 Internal
+// Server Error otherwise.
+func errorStatusCode(ctx context.Context, err error) int {
+	if isRateLimited(err) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func isRateLimited(err error) bool {
+	var rateLimitErr *jira.RateLimitError
+	return errors.As(err, &rateLimitErr)
+}
+
+// writeJiraError responds with the status code for the error from Jira.
+// When rate limited, Jira's Retry-After is passed on, so that the caller
+// (such as newreleases.io) retries later instead of dropping the release.
+func writeJiraError(ctx context.Context, c *gin.Context, err error, body gin.H) {
+	var rateLimitErr *jira.RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		seconds := int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	c.JSON(errorStatusCode(ctx, err), body)
+}
+
+type releaseResult struct {
+	release Release
+	issue   newJiraIssue
+	err     error
+}
+
+// processReleases creates or updates the Jira issues for each release.
+// Failures are logged and returned per release, and do not stop
+// the remaining releases from being processed.
+func (s HTTPServer) processReleases(ctx context.Context, releases []Release) []releaseResult {
+	results := make([]releaseResult, 0, len(releases))
+	for _, release := range releases {
+		issue, err := s.processRelease(ctx, release)
+		results = append(results, releaseResult{release: release, issue: issue, err: err})
+	}
+	return results
+}
+
+// processRelease is the core of jelease, shared by the webhook and trigger
+// endpoints. It filters the release, and searches, deduplicates, and creates
+// or updates the Jira issue for it. Errors and outcomes are logged and
+// counted in the metrics here, so callers only need to respond.
+func (s HTTPServer) processRelease(ctx context.Context, release Release) (newJiraIssue, error) {
+	log.Info().EmbedObject(release).Msg("Received release.")
+
+	if !s.cfg.Filter.Allows(release.Project) {
+		logSkippedRelease(release, skipReasonFilter)
+		return newJiraIssue{SkipReason: skipReasonFilter}, nil
+	}
+	if release.IsPrerelease && s.cfg.Filter.SkipPrereleases {
+		logSkippedRelease(release, skipReasonPrerelease)
+		return newJiraIssue{SkipReason: skipReasonPrerelease}, nil
+	}
+
+	j := s.jiraFor(release)
+	unlock := s.locks.lock(release.Project)
+	issue, err := ensureJiraIssue(ctx, j, release, s.cfg)
+	unlock()
+	if err != nil {
+		log.Error().Err(err).
+			EmbedObject(release).
+			Msg("Failed to create or update Jira issue.")
+		metrics.IncWebhook(metrics.WebhookResultError)
+		s.stats.failed(err)
+		return newJiraIssue{}, err
+	}
+	if issue.SkipReason != "" {
+		logSkippedRelease(release, issue.SkipReason)
+		return issue, nil
+	}
+	log.Info().
+		EmbedObject(release).
+		Str("issue", issue.Key).
+		Str("url", s.issueURL(release, issue.Key)).
+		Str("action", issue.Action()).
+		Strs("duplicates", issue.Duplicates).
+		Msg("Processed release.")
+	metrics.IncWebhook(issue.Action())
+	if issue.Created {
+		s.stats.issueCreated()
+	}
+	if release.HasCVE() && s.cfg.Jira.Issue.Security.Project != "" {
+		// only logged, as failing the webhook would make newreleases.io retry
+		// it, which then skips the release as its regular issue already exists
+		if err := createSecurityIssue(ctx, j, release, &s.cfg.Jira.Issue, issue.IssueRef); err != nil {
+			log.Error().Err(err).
+				EmbedObject(release).
+				Str("issue", issue.Key).
+				Msg("Failed to create linked security issue.")
+			s.stats.failed(err)
+		}
+	}
+	return issue, nil
+}
+
+// logSkippedRelease is the single place to report that nothing was done for
+// a release, so that all the different skip paths are observable the same way.
+func logSkippedRelease(release Release, reason string) {
+	log.Info().
+		EmbedObject(release).
+		Str("reason", reason).
+		Msg("Skipped release.")
+	metrics.IncWebhook(metrics.WebhookResultSkipped)
+}
+
+// jiraFor returns the Jira client to use for the release, based on the
+// configured Jira connection mapping.
+func (s HTTPServer) jiraFor(release Release) jira.Client {
+	name := s.cfg.Jira.ConnectionName(release.Provider, release.Project)
+	if j, ok := s.namedJira[name]; ok {
+		return j
+	}
+	return s.jira
+}
+
+// decodeReleases parses the newreleases.io webhook payload, which is either
+// a single release object, or an array of releases (a batch).
+func decodeReleases(body []byte, strict bool) ([]Release, bool, error) {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		release, err := decodeRelease(body, strict)
+		if err != nil {
+			return nil, false, err
+		}
+		return []Release{release}, false, nil
+	}
+	var rawReleases []json.RawMessage
+	if err := json.Unmarshal(trimmed, &rawReleases); err != nil {
+		return nil, true, err
+	}
+	releases := make([]Release, 0, len(rawReleases))
+	for i, raw := range rawReleases {
+		release, err := decodeRelease(raw, strict)
+		if err != nil {
+			return nil, true, fmt.Errorf("release at index %d: %w", i, err)
+		}
+		releases = append(releases, release)
+	}
+	return releases, true, nil
+}
+
+// decodeRelease parses a single newreleases.io release object. When strict is
+// set, then unknown fields in the payload result in an error. Control
+// characters are stripped from the release's identifying fields.
+func decodeRelease(body []byte, strict bool) (Release, error) {
+	var release Release
+	if !strict {
+		err := binding.JSON.BindBody(body, &release)
+		return release.sanitized(), err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&release); err != nil {
+		return Release{}, err
+	}
+	if err := binding.Validator.ValidateStruct(&release); err != nil {
+		return Release{}, err
+	}
+	return release.sanitized(), nil
+}
+
+// tryApplyChanges creates the PRs for the release, and comments the result on
+// the Jira issue. Meant to be run in the background after the webhook has
+// been responded to, and therefore does not use the request's context.
+func (s HTTPServer) tryApplyChanges(release Release, issueRef jira.IssueRef) {
+	j := s.jiraFor(release)
+	cfg := s.cfg
+	ctx, cancel := s.jiraContext(context.Background())
+	defer cancel()
+
 	tmplCtx := patch.TemplateContext{
 		Package:   release.Project,
 		Version:   release.Version,
@@ -101,13 +537,13 @@ func tryApplyChanges(j jira.Client, release Release, issueRef jira.IssueRef, cfg
 	pkg, ok := cfg.TryFindPackage(release.Project)
 	if !ok {
 		log.Info().Str("project", release.Project).Msg("No package patching config was found. Skipping patching.")
-		createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.NoConfig, tmplCtx)
+		createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.NoConfig, tmplCtx)
 		return
 	}
 	prs, err := patch.CloneAllAndPublishPatches(cfg, pkg.Repos, tmplCtx)
 	if err != nil {
 		log.Error().Err(err).Str("project", release.Project).Msg("Failed creating patches.")
-		createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.PRFailed, TemplateContextError{
+		createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.PRFailed, TemplateContextError{
 			TemplateContext: tmplCtx,
 			Error:           err.Error(),
 		})
@@ -115,7 +551,7 @@ func tryApplyChanges(j jira.Client, release Release, issueRef jira.IssueRef, cfg
 	}
 	if len(prs) == 0 {
 		log.Warn().Str("project", release.Project).Msg("Found package config, but no repositories were patched.")
-		createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.NoPatches, tmplCtx)
+		createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.NoPatches, tmplCtx)
 		return
 	}
 	log.Info().
@@ -123,20 +559,53 @@ func tryApplyChanges(j jira.Client, release Release, issueRef jira.IssueRef, cfg
 		Int("count", len(prs)).
 		Msg("Successfully created PRs for update.")
 
-	createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.PRCreated, TemplateContextPullRequests{
+	createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.PRCreated, TemplateContextPullRequests{
 		TemplateContext: tmplCtx,
 		PullRequests:    prs,
 	})
 }
 
-func createTemplatedComment(j jira.Client, issueRef jira.IssueRef, tmpl *config.Template, tmplCtx any) {
+// trySendSlackNotification sends a Slack message about the created or updated
+// issue, if Slack notifications are enabled. Failures are only logged.
+func (s HTTPServer) trySendSlackNotification(release Release, issue newJiraIssue) {
+	if s.cfg.Slack.WebhookURL == "" || s.cfg.DryRun {
+		return
+	}
+	text, err := s.cfg.Slack.Message.Render(TemplateContextSlack{
+		Release:  release,
+		IssueKey: issue.Key,
+		IssueURL: s.issueURL(release, issue.Key),
+		Action:   issue.Action(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed templating Slack message.")
+		return
+	}
+	if err := slack.Send(context.Background(), s.cfg.Slack.WebhookURL, text); err != nil {
+		log.Error().Err(err).Str("issue", issue.Key).Msg("Failed sending Slack notification.")
+		return
+	}
+	log.Debug().Str("issue", issue.Key).Msg("Sent Slack notification.")
+}
+
+// issueURL returns the link to the issue in the Jira web UI.
+func (s HTTPServer) issueURL(release Release, issueKey string) string {
+	baseURL := s.cfg.Jira.URL
+	name := s.cfg.Jira.ConnectionName(release.Provider, release.Project)
+	if conn, ok := s.cfg.Jira.Connections[name]; ok {
+		baseURL = conn.URL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/browse/" + issueKey
+}
+
+func createTemplatedComment(ctx context.Context, j jira.Client, issueRef jira.IssueRef, tmpl *config.Template, tmplCtx any) {
 	comment, err := tmpl.Render(tmplCtx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed templating Jira issue comment.")
 		return
 	}
 
-	if err := j.CreateIssueComment(issueRef, comment); err != nil {
+	if err := j.CreateIssueComment(ctx, issueRef, comment); err != nil {
 		log.Error().Err(err).Msg("Failed creating Jira issue comment.")
 	}
 }
@@ -146,39 +615,131 @@ type TemplateContextError struct {
 	Error string
 }
 
+type TemplateContextUpdatedIssue struct {
+	patch.TemplateContext
+	Release Release
+}
+
+type TemplateContextDuplicate struct {
+	patch.TemplateContext
+	Original string
+}
+
+type TemplateContextSlack struct {
+	Release  Release
+	IssueKey string
+	IssueURL string
+	Action   string
+}
+
 type TemplateContextPullRequests struct {
 	patch.TemplateContext
 	PullRequests []github.PullRequest
 }
 
+// Reasons for not creating nor updating any issue for a release.
+const (
+	skipReasonFilter         = "filter"
+	skipReasonPrerelease     = "prerelease"
+	skipReasonAlreadyCreated = "alreadyCreated"
+	skipReasonNotNewer       = "notNewer"
+	skipReasonDryRun         = "dryRun"
+)
+
 type newJiraIssue struct {
 	jira.IssueRef
 	Created bool
+	// Existing is set when an issue has already been created for this exact
+	// release, or already has a newer version, and was therefore left
+	// untouched.
+	Existing bool
+	// SkipReason is set when nothing was done for the release,
+	// such as skipReasonDryRun.
+	SkipReason string
+	// Duplicates are the keys of the other issues found for the release,
+	// which were ignored or closed in favor of this issue.
+	Duplicates []string
 }
 
-func ensureJiraIssue(j jira.Client, r Release, cfg *config.Config) (newJiraIssue, error) {
-	existingIssues, err := j.FindIssuesForPackage(r.Project)
-	if err != nil {
-		return newJiraIssue{}, err
+// needsFollowUps returns true if PRs and notifications should be created
+// for the issue. In dry-run mode they are still run, as they skip persisting
+// any changes by themselves.
+func (i newJiraIssue) needsFollowUps() bool {
+	return !i.Existing && (i.SkipReason == "" || i.SkipReason == skipReasonDryRun)
+}
+
+// Action returns a short description of what was done to the issue,
+// intended for logging and metrics.
+func (i newJiraIssue) Action() string {
+	if i.SkipReason != "" {
+		return metrics.WebhookResultSkipped
+	}
+	if i.Created {
+		return metrics.WebhookResultCreated
+	}
+	return metrics.WebhookResultUpdated
+}
+
+func ensureJiraIssue(ctx context.Context, j jira.Client, r Release, cfg *config.Config) (newJiraIssue, error) {
+	var existingIssues []jira.Issue
+	if cfg.Jira.Issue.ReuseExisting {
+		var err error
+		existingIssues, err = findExistingIssues(ctx, j, r, &cfg.Jira.Issue)
+		if err != nil {
+			return newJiraIssue{}, err
+		}
+		if !cfg.Jira.Issue.ReopenClosed {
+			// Closed issues can still be found via the searchTemplate or
+			// searchStatuses, but must only be reopened when enabled.
+			existingIssues = withoutClosedIssues(existingIssues)
+		}
 	}
 
 	if len(existingIssues) == 0 {
 		// no previous issues, create new jira issue
-		i := r.JiraIssue(&cfg.Jira.Issue)
+		i, err := r.JiraIssue(&cfg.Jira.Issue)
+		if err != nil {
+			return newJiraIssue{}, err
+		}
+
+		markerLabel, err := r.IssueMarkerLabel(cfg.Jira.Issue.MarkerLabel)
+		if err != nil {
+			return newJiraIssue{}, err
+		}
+		if markerLabel != "" {
+			markedIssue, ok, err := j.FindIssueWithLabel(ctx, markerLabel)
+			if err != nil {
+				return newJiraIssue{}, err
+			}
+			if ok {
+				log.Debug().
+					Str("issue", markedIssue.Key).
+					Str("label", markerLabel).
+					Msg("Skipping creation of issue, as one already exists for this release.")
+				return newJiraIssue{
+					IssueRef:   markedIssue.IssueRef(),
+					Existing:   true,
+					SkipReason: skipReasonAlreadyCreated,
+				}, nil
+			}
+		}
 
 		if cfg.DryRun {
-			log.Info().
+			log.Debug().
 				Str("issue", i.Key).
 				Msg("Skipping creation of issue because Config.DryRun is enabled.")
 			return newJiraIssue{
-				IssueRef: i.IssueRef(),
-				Created:  false,
+				IssueRef:   i.IssueRef(),
+				Created:    false,
+				SkipReason: skipReasonDryRun,
 			}, nil
 		}
-		issueRef, err := j.CreateIssue(i)
+		issueRef, err := j.CreateIssue(ctx, i)
 		if err != nil {
 			return newJiraIssue{}, err
 		}
+		addIssueWatchers(ctx, j, issueRef, cfg.Jira.Issue.Watchers)
+		transitionCreatedIssue(ctx, j, issueRef, cfg.Jira.Issue.PostCreateTransition)
 		return newJiraIssue{
 			IssueRef: issueRef,
 			Created:  true,
@@ -186,39 +747,368 @@ func ensureJiraIssue(j jira.Client, r Release, cfg *config.Config) (newJiraIssue
 	}
 
 	// in case of duplicate issues, update the oldest (probably original) one, ignore rest as duplicates
-	mostRecentIssue := existingIssues[0]
-	var duplicateIssueKeys []string
-	for _, issue := range existingIssues[1:] {
-		duplicateIssueKeys = append(duplicateIssueKeys, issue.Key)
+	oldestIssue, duplicateIssueKeys := findOldestIssue(existingIssues)
+	if cfg.Jira.Issue.ReopenClosed {
+		oldestIssue, duplicateIssueKeys = findIssueToReopen(existingIssues)
 	}
 
 	if len(duplicateIssueKeys) > 0 {
 		log.Debug().
-			Str("recent", mostRecentIssue.Key).
+			Str("oldest", oldestIssue.Key).
 			Strs("duplicates", duplicateIssueKeys).
-			Msg("Ignoring the duplicate issues in favor of recent issue.")
+			Msg("Ignoring the duplicate issues in favor of oldest issue.")
+	}
+
+	if current, ok := findVersionInSummary(oldestIssue.Summary); ok {
+		incoming, err := version.Parse(r.Version)
+		if err == nil && incoming.Compare(current) <= 0 {
+			log.Debug().
+				Str("issue", oldestIssue.Key).
+				Str("currentVersion", current.String()).
+				Msg("Skipping update of issue, as it already has the same or a newer version.")
+			return newJiraIssue{
+				IssueRef:   oldestIssue.IssueRef(),
+				Existing:   true,
+				SkipReason: skipReasonNotNewer,
+			}, nil
+		}
 	}
 
 	if cfg.DryRun {
-		log.Info().
-			Str("issue", mostRecentIssue.Key).
+		log.Debug().
+			Str("issue", oldestIssue.Key).
 			Msg("Skipping update of issue because Config.DryRun is enabled.")
 		return newJiraIssue{
-			IssueRef: mostRecentIssue.IssueRef(),
-			Created:  false,
+			IssueRef:   oldestIssue.IssueRef(),
+			Created:    false,
+			SkipReason: skipReasonDryRun,
 		}, nil
 	}
-	issueRef := mostRecentIssue.IssueRef()
-	if err := j.UpdateIssueSummary(issueRef, r.IssueSummary()); err != nil {
-		return newJiraIssue{}, err
+	if cfg.Jira.Issue.CloseDuplicates {
+		closeDuplicateIssues(ctx, j, r, cfg, oldestIssue, existingIssues, duplicateIssueKeys)
+	} else if cfg.Jira.Issue.CommentDuplicates {
+		commentDuplicateIssues(ctx, j, r, cfg, oldestIssue, existingIssues, duplicateIssueKeys)
+	}
+	issueRef := oldestIssue.IssueRef()
+	if cfg.Jira.Issue.ReopenClosed && oldestIssue.IsClosed() {
+		if err := j.TransitionIssue(ctx, issueRef, cfg.Jira.Issue.Status); err != nil {
+			return newJiraIssue{}, fmt.Errorf("reopen closed issue: %w", err)
+		}
+	}
+	if cfg.Jira.Issue.UpdateLabels {
+		labels, err := missingIssueLabels(oldestIssue, r, &cfg.Jira.Issue)
+		if err != nil {
+			return newJiraIssue{}, err
+		}
+		if err := j.AddIssueLabels(ctx, issueRef, labels); err != nil {
+			return newJiraIssue{}, err
+		}
+	}
+	updateMode := cfg.Jira.Issue.UpdateMode
+	updatesSummary, updatesComment := updateMode.UpdatesSummary(), updateMode.UpdatesComment()
+	if updatesSummary && cfg.Jira.Issue.ProtectEditedSummaries && !r.IsGeneratedSummary(oldestIssue.Summary, &cfg.Jira.Issue) {
+		log.Info().
+			Str("issue", oldestIssue.Key).
+			Str("summary", oldestIssue.Summary).
+			Msg("Skipping update of issue summary, as it has been edited manually. Only adding a comment.")
+		updatesSummary, updatesComment = false, true
+	}
+	if updatesSummary {
+		summary, err := r.IssueSummary(&cfg.Jira.Issue)
+		if err != nil {
+			return newJiraIssue{}, err
+		}
+		if err := j.UpdateIssueSummary(ctx, issueRef, summary); err != nil {
+			return newJiraIssue{}, err
+		}
+	}
+	if updatesComment {
+		createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.UpdatedIssue, TemplateContextUpdatedIssue{
+			TemplateContext: patch.TemplateContext{
+				Package:   r.Project,
+				Version:   r.Version,
+				JiraIssue: issueRef.Key,
+			},
+			Release: r,
+		})
 	}
-	createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.UpdatedIssue, patch.TemplateContext{
-		Package:   r.Project,
-		Version:   r.Version,
-		JiraIssue: issueRef.Key,
-	})
 	return newJiraIssue{
-		IssueRef: mostRecentIssue.IssueRef(),
-		Created:  false,
+		IssueRef:   oldestIssue.IssueRef(),
+		Created:    false,
+		Duplicates: duplicateIssueKeys,
 	}, nil
 }
+
+// addIssueWatchers adds the users as watchers on the issue. Errors are only
+// logged, as the issue has already been created.
+func addIssueWatchers(ctx context.Context, j jira.Client, issueRef jira.IssueRef, watchers []string) {
+	for _, watcher := range watchers {
+		if err := j.AddIssueWatcher(ctx, issueRef, watcher); err != nil {
+			log.Warn().
+				Err(err).
+				Str("issue", issueRef.Key).
+				Str("watcher", watcher).
+				Msg("Failed to add watcher to issue.")
+		}
+	}
+}
+
+// transitionCreatedIssue moves the created issue on to the status, if set.
+// Errors are only logged, as the issue has already been created.
+func transitionCreatedIssue(ctx context.Context, j jira.Client, issueRef jira.IssueRef, status string) {
+	if status == "" {
+		return
+	}
+	if err := j.TransitionIssue(ctx, issueRef, status); err != nil {
+		log.Warn().
+			Err(err).
+			Str("issue", issueRef.Key).
+			Str("status", status).
+			Msg("Failed to transition created issue. Leaving it in its initial status.")
+	}
+}
+
+// createSecurityIssue creates the additional issue in the security project,
+// and links it to the regular issue of the release.
+func createSecurityIssue(ctx context.Context, j jira.Client, r Release, cfg *config.JiraIssue, issueRef jira.IssueRef) error {
+	issue, err := r.SecurityJiraIssue(cfg)
+	if err != nil {
+		return err
+	}
+	securityRef, err := j.CreateIssue(ctx, issue)
+	if err != nil {
+		return err
+	}
+	addIssueWatchers(ctx, j, securityRef, cfg.Watchers)
+	if err := j.LinkIssues(ctx, cfg.Security.LinkType, issueRef, securityRef); err != nil {
+		return fmt.Errorf("link security issue %s: %w", securityRef.Key, err)
+	}
+	return nil
+}
+
+// TemplateContextSearch is the data available in the
+// Config.Jira.Issue.SearchTemplate template.
+type TemplateContextSearch struct {
+	Release
+	ProjectKey             string
+	Statuses               []string
+	ManagedLabel           string
+	MatchLabel             string
+	ProjectNameCustomField uint
+}
+
+// findExistingIssues searches for the existing issues to update for the
+// release, using the configured search template if it renders to a non-empty
+// JQL query, or the built-in query otherwise.
+func findExistingIssues(ctx context.Context, j jira.Client, r Release, cfg *config.JiraIssue) ([]jira.Issue, error) {
+	matchLabel := r.MatchLabel(cfg.MatchStrategy)
+	projectKey := cfg.ProjectForProvider(r.Provider)
+	if cfg.SearchTemplate != nil {
+		jql, err := cfg.SearchTemplate.Render(TemplateContextSearch{
+			Release:                r,
+			ProjectKey:             projectKey,
+			Statuses:               cfg.StatusesToSearch(),
+			ManagedLabel:           cfg.ManagedLabel,
+			MatchLabel:             matchLabel,
+			ProjectNameCustomField: cfg.ProjectNameCustomField,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("render issue search template: %w", err)
+		}
+		if jql = strings.TrimSpace(jql); jql != "" {
+			log.Debug().Str("jql", jql).Msg("Searching for existing issues using search template.")
+			return j.FindIssuesWithJQL(ctx, jql)
+		}
+	}
+	return j.FindIssuesForPackage(ctx, projectKey, r.Project, matchLabel)
+}
+
+// missingIssueLabels returns the labels that a newly created issue would get,
+// but that the existing issue lacks.
+func missingIssueLabels(issue jira.Issue, r Release, cfg *config.JiraIssue) ([]string, error) {
+	labels, err := r.IssueLabels(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ProjectNameCustomField == 0 {
+		// Package name is stored as a label when not using a custom field
+		labels = append(labels, r.Project)
+	}
+	var missing []string
+	for _, label := range labels {
+		if !slices.Contains(issue.Labels, label) && !slices.Contains(missing, label) {
+			missing = append(missing, label)
+		}
+	}
+	return missing, nil
+}
+
+// closeDuplicateIssues comments on and transitions the duplicate issues to
+// the configured duplicate status. Only issues that have the managed label
+// are closed, to not touch issues created by humans.
+// Failures are only logged, as they should not prevent updating the original.
+func closeDuplicateIssues(ctx context.Context, j jira.Client, r Release, cfg *config.Config, original jira.Issue, issues []jira.Issue, duplicateKeys []string) {
+	for _, issue := range issues {
+		if !slices.Contains(duplicateKeys, issue.Key) || issue.IsClosed() {
+			continue
+		}
+		if !isManagedIssue(issue, cfg.Jira.Issue.ManagedLabel) {
+			log.Debug().
+				Str("issue", issue.Key).
+				Str("label", cfg.Jira.Issue.ManagedLabel).
+				Msg("Not closing duplicate issue, as it lacks the managed label.")
+			continue
+		}
+		issueRef := issue.IssueRef()
+		if cfg.Jira.Issue.MergeDuplicates {
+			if err := mergeDuplicateIssue(ctx, j, original, issue); err != nil {
+				log.Error().Err(err).
+					Str("issue", issue.Key).
+					Str("original", original.Key).
+					Msg("Failed merging duplicate issue. Not closing it.")
+				continue
+			}
+		}
+		createTemplatedComment(ctx, j, issueRef, cfg.Jira.Issue.Comments.Duplicate, TemplateContextDuplicate{
+			TemplateContext: patch.TemplateContext{
+				Package:   r.Project,
+				Version:   r.Version,
+				JiraIssue: issue.Key,
+			},
+			Original: original.Key,
+		})
+		if err := j.TransitionIssue(ctx, issueRef, cfg.Jira.Issue.DuplicateStatus); err != nil {
+			log.Error().Err(err).
+				Str("issue", issue.Key).
+				Msg("Failed closing duplicate issue.")
+			continue
+		}
+		log.Info().
+			Str("issue", issue.Key).
+			Str("original", original.Key).
+			Msg("Closed duplicate issue.")
+	}
+}
+
+// mergeDuplicateIssue copies the description and comments of the duplicate
+// onto the original issue, as a single comment.
+func mergeDuplicateIssue(ctx context.Context, j jira.Client, original, duplicate jira.Issue) error {
+	comments, err := j.GetIssueComments(ctx, duplicate.IssueRef())
+	if err != nil {
+		return err
+	}
+	body, ok := mergedDuplicateComment(duplicate, comments)
+	if !ok {
+		return nil
+	}
+	if err := j.CreateIssueComment(ctx, original.IssueRef(), body); err != nil {
+		return err
+	}
+	log.Info().
+		Str("issue", duplicate.Key).
+		Str("original", original.Key).
+		Msg("Merged duplicate issue into original issue.")
+	return nil
+}
+
+// mergedDuplicateComment returns the comment, in Jira wiki markup, that
+// copies the duplicate's description and unrestricted comments. Returns
+// false if there is nothing to copy.
+func mergedDuplicateComment(duplicate jira.Issue, comments []jira.Comment) (string, bool) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "(i) Merged from duplicate issue %s: %s", duplicate.Key, duplicate.Summary)
+	var merged bool
+	if description := strings.TrimSpace(duplicate.Description); description != "" {
+		fmt.Fprintf(&sb, "\n\n*Description:*\n{quote}%s{quote}", description)
+		merged = true
+	}
+	for _, comment := range comments {
+		if comment.Restricted || strings.TrimSpace(comment.Body) == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n\n*Comment by %s", comment.Author)
+		if !comment.Created.IsZero() {
+			fmt.Fprintf(&sb, " on %s", comment.Created.Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(&sb, ":*\n{quote}%s{quote}", strings.TrimSpace(comment.Body))
+		merged = true
+	}
+	return sb.String(), merged
+}
+
+// commentDuplicateIssues adds a comment on the open duplicate issues that
+// points to the original issue, without closing them.
+func commentDuplicateIssues(ctx context.Context, j jira.Client, r Release, cfg *config.Config, original jira.Issue, issues []jira.Issue, duplicateKeys []string) {
+	for _, issue := range issues {
+		if !slices.Contains(duplicateKeys, issue.Key) || issue.IsClosed() {
+			continue
+		}
+		createTemplatedComment(ctx, j, issue.IssueRef(), cfg.Jira.Issue.Comments.Superseded, TemplateContextDuplicate{
+			TemplateContext: patch.TemplateContext{
+				Package:   r.Project,
+				Version:   r.Version,
+				JiraIssue: issue.Key,
+			},
+			Original: original.Key,
+		})
+	}
+}
+
+// isManagedIssue reports whether the issue was created by jelease,
+// identified by the managed label. Returns false if no managed label is
+// configured, as then there's nothing to identify the issue by.
+func isManagedIssue(issue jira.Issue, managedLabel string) bool {
+	return managedLabel != "" && slices.Contains(issue.Labels, managedLabel)
+}
+
+// findOldestIssue returns the issue with the earliest creation date,
+// as well as the keys of all other issues, treated as duplicates.
+// The issues do not have to be sorted.
+func findOldestIssue(issues []jira.Issue) (jira.Issue, []string) {
+	if len(issues) == 0 {
+		return jira.Issue{}, nil
+	}
+	oldestIndex := 0
+	for i, issue := range issues {
+		if issue.Created.Before(issues[oldestIndex].Created) {
+			oldestIndex = i
+		}
+	}
+	var duplicateKeys []string
+	for i, issue := range issues {
+		if i != oldestIndex {
+			duplicateKeys = append(duplicateKeys, issue.Key)
+		}
+	}
+	return issues[oldestIndex], duplicateKeys
+}
+
+// withoutClosedIssues returns the issues that are not closed.
+func withoutClosedIssues(issues []jira.Issue) []jira.Issue {
+	var open []jira.Issue
+	for _, issue := range issues {
+		if !issue.IsClosed() {
+			open = append(open, issue)
+		}
+	}
+	return open
+}
+
+// findIssueToReopen is like findOldestIssue, but ignores closed issues.
+// If all issues are closed, then the most recently created one is returned
+// without any duplicates, as closed issues are not considered duplicates.
+func findIssueToReopen(issues []jira.Issue) (jira.Issue, []string) {
+	var openIssues []jira.Issue
+	var newestClosed jira.Issue
+	for _, issue := range issues {
+		if !issue.IsClosed() {
+			openIssues = append(openIssues, issue)
+		} else if newestClosed.Key == "" || issue.Created.After(newestClosed.Created) {
+			newestClosed = issue
+		}
+	}
+	if len(openIssues) > 0 {
+		return findOldestIssue(openIssues)
+	}
+	return newestClosed, nil
+}
@@ -0,0 +1,908 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+func TestFindOldestIssue(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, time.December, d, 0, 0, 0, 0, time.UTC)
+	}
+	issues := []jira.Issue{
+		{Key: "OP-3", Created: day(3)},
+		{Key: "OP-1", Created: day(1)},
+		{Key: "OP-4", Created: day(4)},
+		{Key: "OP-2", Created: day(2)},
+	}
+
+	oldest, duplicates := findOldestIssue(issues)
+
+	if oldest.Key != "OP-1" {
+		t.Errorf("want oldest %q, got %q", "OP-1", oldest.Key)
+	}
+	wantDuplicates := []string{"OP-3", "OP-4", "OP-2"}
+	if !slices.Equal(wantDuplicates, duplicates) {
+		t.Errorf("want duplicates %v, got %v", wantDuplicates, duplicates)
+	}
+}
+
+func TestFindOldestIssue_single(t *testing.T) {
+	issues := []jira.Issue{{Key: "OP-1"}}
+
+	oldest, duplicates := findOldestIssue(issues)
+
+	if oldest.Key != "OP-1" {
+		t.Errorf("want oldest %q, got %q", "OP-1", oldest.Key)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("want no duplicates, got %v", duplicates)
+	}
+}
+
+func TestFindIssueToReopen(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, time.December, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name           string
+		issues         []jira.Issue
+		wantKey        string
+		wantDuplicates []string
+	}{
+		{
+			name: "prefers open issues",
+			issues: []jira.Issue{
+				{Key: "OP-1", Created: day(1), StatusKey: "done"},
+				{Key: "OP-3", Created: day(3), StatusKey: "new"},
+				{Key: "OP-2", Created: day(2), StatusKey: "indeterminate"},
+			},
+			wantKey:        "OP-2",
+			wantDuplicates: []string{"OP-3"},
+		},
+		{
+			name: "newest closed",
+			issues: []jira.Issue{
+				{Key: "OP-1", Created: day(1), StatusKey: "done"},
+				{Key: "OP-3", Created: day(3), StatusKey: "done"},
+				{Key: "OP-2", Created: day(2), StatusKey: "done"},
+			},
+			wantKey: "OP-3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, duplicates := findIssueToReopen(tc.issues)
+			if got.Key != tc.wantKey {
+				t.Errorf("want %q, got %q", tc.wantKey, got.Key)
+			}
+			if !slices.Equal(tc.wantDuplicates, duplicates) {
+				t.Errorf("want duplicates %v, got %v", tc.wantDuplicates, duplicates)
+			}
+		})
+	}
+}
+
+func TestIsManagedIssue(t *testing.T) {
+	tests := []struct {
+		name         string
+		managedLabel string
+		want         bool
+	}{
+		{name: "has label", managedLabel: "jelease", want: true},
+		{name: "missing label", managedLabel: "other", want: false},
+		{name: "no managed label", managedLabel: "", want: false},
+	}
+	issue := jira.Issue{Key: "OP-1", Labels: []string{"jelease", "update", "neuvector"}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isManagedIssue(issue, tc.managedLabel); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+// fakeJira is a fake [jira.Client] that records the calls made to it.
+// Methods not overridden here will panic when called.
+type fakeJira struct {
+	jira.Client
+
+	existingIssues []jira.Issue
+	created        []jira.Issue
+	updated        []jira.IssueRef
+	comments       map[string][]string
+	watchers       []string
+	transitions    []string
+	links          []string
+	issueComments  map[string][]jira.Comment
+	jql            string
+}
+
+func (f *fakeJira) FindIssuesForPackage(ctx context.Context, projectKey, packageName, matchLabel string) ([]jira.Issue, error) {
+	return f.existingIssues, nil
+}
+
+func (f *fakeJira) FindIssuesWithJQL(ctx context.Context, jql string) ([]jira.Issue, error) {
+	f.jql = jql
+	return f.existingIssues, nil
+}
+
+func (f *fakeJira) FindIssueWithLabel(ctx context.Context, label string) (jira.Issue, bool, error) {
+	return jira.Issue{}, false, nil
+}
+
+func (f *fakeJira) CreateIssue(ctx context.Context, issue jira.Issue) (jira.IssueRef, error) {
+	f.created = append(f.created, issue)
+	return jira.IssueRef{ID: "10001", Key: "OP-1"}, nil
+}
+
+func (f *fakeJira) UpdateIssueSummary(ctx context.Context, issueRef jira.IssueRef, newSummary string) error {
+	f.updated = append(f.updated, issueRef)
+	return nil
+}
+
+func (f *fakeJira) CreateIssueComment(ctx context.Context, issueRef jira.IssueRef, newComment string) error {
+	if f.comments == nil {
+		f.comments = map[string][]string{}
+	}
+	f.comments[issueRef.Key] = append(f.comments[issueRef.Key], newComment)
+	return nil
+}
+
+func (f *fakeJira) AddIssueWatcher(ctx context.Context, issueRef jira.IssueRef, user string) error {
+	if user == "unknown" {
+		return errors.New("user does not exist")
+	}
+	f.watchers = append(f.watchers, user)
+	return nil
+}
+
+func (f *fakeJira) GetIssueComments(ctx context.Context, issueRef jira.IssueRef) ([]jira.Comment, error) {
+	return f.issueComments[issueRef.Key], nil
+}
+
+func (f *fakeJira) LinkIssues(ctx context.Context, linkType string, inward, outward jira.IssueRef) error {
+	f.links = append(f.links, fmt.Sprintf("%s %s %s", inward.Key, linkType, outward.Key))
+	return nil
+}
+
+func (f *fakeJira) TransitionIssue(ctx context.Context, issueRef jira.IssueRef, statusName string) error {
+	if statusName == "Unavailable" {
+		return errors.New("no transition found")
+	}
+	f.transitions = append(f.transitions, statusName)
+	return nil
+}
+
+func TestEnsureJiraIssue_create(t *testing.T) {
+	j := &fakeJira{}
+	cfg := &config.Config{}
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Created {
+		t.Error("want issue to be created, but was not")
+	}
+	if len(j.created) != 1 {
+		t.Errorf("want 1 created issue, got %d", len(j.created))
+	}
+	if len(j.updated) != 0 {
+		t.Errorf("want 0 updated issues, got %d", len(j.updated))
+	}
+}
+
+func TestEnsureJiraIssue_watchers(t *testing.T) {
+	j := &fakeJira{}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.Watchers = []string{"alice", "unknown", "bob"}
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatalf("want failed watchers to be ignored, got error: %s", err)
+	}
+	if !got.Created {
+		t.Error("want issue to be created, but was not")
+	}
+	want := []string{"alice", "bob"}
+	if !slices.Equal(want, j.watchers) {
+		t.Errorf("want watchers %v, got %v", want, j.watchers)
+	}
+}
+
+func TestEnsureJiraIssue_postCreateTransition(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   []string
+	}{
+		{name: "disabled"},
+		{name: "available", status: "Backlog", want: []string{"Backlog"}},
+		{name: "unavailable", status: "Unavailable"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{}
+			cfg := &config.Config{}
+			cfg.Jira.Issue.PostCreateTransition = tc.status
+
+			got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+			if err != nil {
+				t.Fatalf("want failed transition to be ignored, got error: %s", err)
+			}
+			if !got.Created {
+				t.Error("want issue to be created, but was not")
+			}
+			if !slices.Equal(tc.want, j.transitions) {
+				t.Errorf("want transitions %v, got %v", tc.want, j.transitions)
+			}
+		})
+	}
+}
+
+func TestCreateSecurityIssue(t *testing.T) {
+	j := &fakeJira{}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.Project = "OP"
+	cfg.Jira.Issue.ProjectMapping = map[string]string{"github": "OPS"}
+	cfg.Jira.Issue.ParentKey = "OPS-100"
+	cfg.Jira.Issue.SecurityType = "Vulnerability"
+	cfg.Jira.Issue.Security.Project = "SEC"
+	cfg.Jira.Issue.Security.LinkType = "Relates"
+	release := Release{Provider: "github", Project: "neuvector", Version: "v5.1.3", CVE: []string{"CVE-2022-1234"}}
+
+	err := createSecurityIssue(context.Background(), j, release, &cfg.Jira.Issue, jira.IssueRef{ID: "10002", Key: "OPS-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.created) != 1 {
+		t.Fatalf("want 1 created issue, got %d", len(j.created))
+	}
+	created := j.created[0]
+	if created.ProjectKey != "SEC" || created.ParentKey != "" || created.TypeName != "Vulnerability" {
+		t.Errorf("want issue of type Vulnerability in project SEC without parent, got type %q in %q with parent %q",
+			created.TypeName, created.ProjectKey, created.ParentKey)
+	}
+	want := []string{"OPS-2 Relates OP-1"}
+	if !slices.Equal(want, j.links) {
+		t.Errorf("want links %v, got %v", want, j.links)
+	}
+}
+
+func TestMergedDuplicateComment(t *testing.T) {
+	duplicate := jira.Issue{Key: "OP-3", Summary: "Update neuvector to v5.1.2", Description: "Needs a DB migration.\n"}
+	comments := []jira.Comment{
+		{Author: "Alice", Created: time.Date(2023, 3, 17, 15, 4, 5, 0, time.UTC), Body: "Blocked by OP-42."},
+		{Author: "Bob", Body: "Only for admins.", Restricted: true},
+		{Author: "Carol", Body: "  "},
+	}
+	got, ok := mergedDuplicateComment(duplicate, comments)
+	if !ok {
+		t.Fatal("want comment to be merged, got nothing")
+	}
+	want := "(i) Merged from duplicate issue OP-3: Update neuvector to v5.1.2\n\n" +
+		"*Description:*\n{quote}Needs a DB migration.{quote}\n\n" +
+		"*Comment by Alice on 2023-03-17 15:04:*\n{quote}Blocked by OP-42.{quote}"
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	if _, ok := mergedDuplicateComment(jira.Issue{Key: "OP-4"}, comments[1:]); ok {
+		t.Error("want nothing to merge for issue without description and visible comments")
+	}
+}
+
+func TestCloseDuplicateIssues_merge(t *testing.T) {
+	j := &fakeJira{
+		issueComments: map[string][]jira.Comment{
+			"OP-3": {{Author: "Alice", Body: "Blocked by OP-42."}},
+		},
+	}
+	var tmpl config.Template
+	tmpl.Set("Duplicate of {{ .Original }}")
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ManagedLabel = "jelease"
+	cfg.Jira.Issue.DuplicateStatus = "Done"
+	cfg.Jira.Issue.MergeDuplicates = true
+	cfg.Jira.Issue.Comments.Duplicate = &tmpl
+	original := jira.Issue{ID: "10002", Key: "OP-2", Labels: []string{"jelease"}}
+	duplicate := jira.Issue{ID: "10003", Key: "OP-3", Labels: []string{"jelease"}}
+
+	closeDuplicateIssues(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg,
+		original, []jira.Issue{original, duplicate}, []string{"OP-3"})
+
+	if len(j.comments["OP-2"]) != 1 || !strings.Contains(j.comments["OP-2"][0], "Blocked by OP-42.") {
+		t.Errorf("want merged comment on original issue, got %v", j.comments["OP-2"])
+	}
+	if want := []string{"Duplicate of OP-2"}; !slices.Equal(want, j.comments["OP-3"]) {
+		t.Errorf("want comments %v on duplicate, got %v", want, j.comments["OP-3"])
+	}
+	if want := []string{"Done"}; !slices.Equal(want, j.transitions) {
+		t.Errorf("want transitions %v, got %v", want, j.transitions)
+	}
+}
+
+func TestEnsureJiraIssue_update(t *testing.T) {
+	j := &fakeJira{
+		existingIssues: []jira.Issue{
+			{ID: "10002", Key: "OP-2", PackageName: "neuvector"},
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ReuseExisting = true
+	cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Created {
+		t.Error("want issue to be updated, but was created")
+	}
+	if got.Key != "OP-2" {
+		t.Errorf("want issue OP-2, got %q", got.Key)
+	}
+	if len(j.created) != 0 {
+		t.Errorf("want 0 created issues, got %d", len(j.created))
+	}
+	if len(j.updated) != 1 {
+		t.Errorf("want 1 updated issue, got %d", len(j.updated))
+	}
+}
+
+func TestEnsureJiraIssue_updateModeComment(t *testing.T) {
+	j := &fakeJira{
+		existingIssues: []jira.Issue{
+			{ID: "10002", Key: "OP-2", PackageName: "neuvector", Summary: "Update neuvector to v5.1.2"},
+		},
+	}
+	var tmpl config.Template
+	tmpl.Set("Updated to {{ .Version }}")
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ReuseExisting = true
+	cfg.Jira.Issue.UpdateMode = config.UpdateModeComment
+	cfg.Jira.Issue.Comments.UpdatedIssue = &tmpl
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Created {
+		t.Error("want issue to be updated, but was created")
+	}
+	if len(j.updated) != 0 {
+		t.Errorf("want summary to be left as-is, got %d summary updates", len(j.updated))
+	}
+	want := []string{"Updated to v5.1.3"}
+	if !slices.Equal(want, j.comments["OP-2"]) {
+		t.Errorf("want comments %v, got %v", want, j.comments["OP-2"])
+	}
+}
+
+func TestEnsureJiraIssue_protectEditedSummaries(t *testing.T) {
+	tests := []struct {
+		name        string
+		summary     string
+		wantUpdated int
+	}{
+		{name: "generated", summary: "Update neuvector to version v5.1.2", wantUpdated: 1},
+		{name: "edited", summary: "Update neuvector to version v5.1.2 (needs DB migration)", wantUpdated: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{
+				existingIssues: []jira.Issue{
+					{ID: "10002", Key: "OP-2", PackageName: "neuvector", Summary: tc.summary},
+				},
+			}
+			var tmpl config.Template
+			tmpl.Set("Updated to {{ .Version }}")
+			cfg := &config.Config{}
+			cfg.Jira.Issue.ReuseExisting = true
+			cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+			cfg.Jira.Issue.ProtectEditedSummaries = true
+			cfg.Jira.Issue.Comments.UpdatedIssue = &tmpl
+
+			if _, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg); err != nil {
+				t.Fatal(err)
+			}
+			if len(j.updated) != tc.wantUpdated {
+				t.Errorf("want %d summary updates, got %d", tc.wantUpdated, len(j.updated))
+			}
+			wantComments := 1 - tc.wantUpdated
+			if len(j.comments["OP-2"]) != wantComments {
+				t.Errorf("want %d comments, got %v", wantComments, j.comments["OP-2"])
+			}
+		})
+	}
+}
+
+func TestEnsureJiraIssue_reopenClosed(t *testing.T) {
+	tests := []struct {
+		name            string
+		reopenClosed    bool
+		wantCreated     bool
+		wantTransitions []string
+	}{
+		{name: "disabled", wantCreated: true},
+		{name: "enabled", reopenClosed: true, wantTransitions: []string{"Open"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{
+				existingIssues: []jira.Issue{
+					// e.g found via searchStatuses: [Done]
+					{ID: "10002", Key: "OP-2", PackageName: "neuvector", Summary: "Update neuvector to v5.1.2", StatusKey: "done"},
+				},
+			}
+			cfg := &config.Config{}
+			cfg.Jira.Issue.ReuseExisting = true
+			cfg.Jira.Issue.ReopenClosed = tc.reopenClosed
+			cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+			cfg.Jira.Issue.Status = "Open"
+
+			got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Created != tc.wantCreated {
+				t.Errorf("want created %t, got %t", tc.wantCreated, got.Created)
+			}
+			if !slices.Equal(tc.wantTransitions, j.transitions) {
+				t.Errorf("want transitions %v, got %v", tc.wantTransitions, j.transitions)
+			}
+		})
+	}
+}
+
+func TestEnsureJiraIssue_reuseExisting(t *testing.T) {
+	tests := []struct {
+		name          string
+		reuseExisting bool
+		wantCreated   bool
+	}{
+		{name: "reuse", reuseExisting: true, wantCreated: false},
+		{name: "always create", reuseExisting: false, wantCreated: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{
+				existingIssues: []jira.Issue{
+					{ID: "10002", Key: "OP-2", PackageName: "neuvector"},
+				},
+			}
+			cfg := &config.Config{}
+			cfg.Jira.Issue.ReuseExisting = tc.reuseExisting
+			cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+
+			got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Created != tc.wantCreated {
+				t.Errorf("want created=%t, got %t", tc.wantCreated, got.Created)
+			}
+			wantCreatedCount := 0
+			if tc.wantCreated {
+				wantCreatedCount = 1
+			}
+			if len(j.created) != wantCreatedCount {
+				t.Errorf("want %d created issues, got %d", wantCreatedCount, len(j.created))
+			}
+		})
+	}
+}
+
+func TestEnsureJiraIssue_commentDuplicates(t *testing.T) {
+	j := &fakeJira{
+		existingIssues: []jira.Issue{
+			{ID: "10002", Key: "OP-2", PackageName: "neuvector", Created: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: "10003", Key: "OP-3", PackageName: "neuvector", Created: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	var tmpl config.Template
+	if err := tmpl.Set("Superseded by {{ .Original }}"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ReuseExisting = true
+	cfg.Jira.Issue.CommentDuplicates = true
+	cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+	cfg.Jira.Issue.Comments.Superseded = &tmpl
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Duplicates, []string{"OP-3"}) {
+		t.Errorf("want duplicates [OP-3], got %v", got.Duplicates)
+	}
+	want := map[string][]string{"OP-3": {"Superseded by OP-2"}}
+	if !reflect.DeepEqual(want, j.comments) {
+		t.Errorf("want comments %v, got %v", want, j.comments)
+	}
+}
+
+func TestEnsureJiraIssue_skipsDowngrade(t *testing.T) {
+	j := &fakeJira{
+		existingIssues: []jira.Issue{
+			{ID: "10002", Key: "OP-2", Summary: "Update neuvector to v5.1.4", PackageName: "neuvector"},
+		},
+	}
+	cfg := &config.Config{}
+	cfg.Jira.Issue.ReuseExisting = true
+	cfg.Jira.Issue.UpdateMode = config.UpdateModeSummary
+
+	got, err := ensureJiraIssue(context.Background(), j, Release{Project: "neuvector", Version: "v5.1.3"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Existing {
+		t.Error("want issue to be left as existing, but was not")
+	}
+	if len(j.updated) != 0 {
+		t.Errorf("want 0 updated issues, got %d", len(j.updated))
+	}
+}
+
+func TestFindVersionInSummary(t *testing.T) {
+	tests := []struct {
+		summary string
+		want    string
+		wantOK  bool
+	}{
+		{summary: "Update neuvector to v5.1.3", want: "v5.1.3", wantOK: true},
+		{summary: "Update neuvector to 5.1.3 (2 CVEs)", want: "5.1.3", wantOK: true},
+		{summary: "Update postgres (15.1)", want: "15.1", wantOK: true},
+		{summary: "Update neuvector", wantOK: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.summary, func(t *testing.T) {
+			got, ok := findVersionInSummary(tc.summary)
+			if ok != tc.wantOK {
+				t.Fatalf("want ok=%t, got %t", tc.wantOK, ok)
+			}
+			if ok && got.String() != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFindExistingIssues_searchTemplate(t *testing.T) {
+	config.FuncsMap = map[string]any{"jqlQuote": jira.QuoteJQL}
+	defer func() { config.FuncsMap = nil }()
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "templated",
+			template: `status in ({{ range $i, $s := .Statuses }}{{ if $i }}, {{ end }}{{ jqlQuote $s }}{{ end }}) and summary ~ {{ jqlQuote .Project }}`,
+			want:     `status in ("Backlog", "In Progress") and summary ~ "neuvector"`,
+		},
+		{
+			name:     "empty uses built-in query",
+			template: "",
+			want:     "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var tmpl config.Template
+			if err := tmpl.Set(tc.template); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.JiraIssue{
+				Status:         "Backlog",
+				SearchStatuses: []string{"In Progress"},
+				SearchTemplate: &tmpl,
+			}
+			j := &fakeJira{}
+			if _, err := findExistingIssues(context.Background(), j, Release{Project: "neuvector"}, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if j.jql != tc.want {
+				t.Errorf("want JQL %q, got %q", tc.want, j.jql)
+			}
+		})
+	}
+}
+
+func TestDecodeRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		strict  bool
+		wantErr bool
+	}{
+		{name: "lenient/known", body: `{"project":"neuvector"}`},
+		{name: "lenient/unknown", body: `{"project":"neuvector","foo":1}`},
+		{name: "strict/example", body: readExampleWebhook(t), strict: true},
+		{name: "strict/unknown", body: `{"project":"neuvector","foo":1}`, strict: true, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			release, err := decodeRelease([]byte(tc.body), tc.strict)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if release.Project != "neuvector" {
+				t.Errorf("want project %q, got %q", "neuvector", release.Project)
+			}
+		})
+	}
+}
+
+func readExampleWebhook(t *testing.T) string {
+	t.Helper()
+	body, err := os.ReadFile("../../examples/newreleasesio-webhook.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestDecodeReleases(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantBatch bool
+		want      []string
+	}{
+		{name: "single", body: `{"project":"neuvector"}`, want: []string{"neuvector"}},
+		{name: "empty array", body: ` []`, wantBatch: true, want: []string{}},
+		{name: "multiple", body: `[{"project":"neuvector"},{"project":"redis"}]`, wantBatch: true, want: []string{"neuvector", "redis"}},
+		{name: "control characters", body: `[{"project":"neu\nvector\t"},{"project":"re\u0000dis"}]`, wantBatch: true, want: []string{"neuvector", "redis"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			releases, isBatch, err := decodeReleases([]byte(tc.body), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isBatch != tc.wantBatch {
+				t.Errorf("want batch %t, got %t", tc.wantBatch, isBatch)
+			}
+			got := []string{}
+			for _, r := range releases {
+				got = append(got, r.Project)
+			}
+			if !slices.Equal(tc.want, got) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestProcessReleases(t *testing.T) {
+	j := &fakeJira{}
+	cfg := &config.Config{}
+	cfg.Filter.Denylist = []string{"redis"}
+	s := HTTPServer{cfg: cfg, jira: j}
+
+	results := s.processReleases(context.Background(), []Release{
+		{Project: "neuvector", Version: "v5.1.3"},
+		{Project: "redis", Version: "7.0.8"},
+		{Project: "postgres", Version: "15.1"},
+	})
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+	if !results[0].issue.Created || !results[2].issue.Created {
+		t.Error("want neuvector and postgres issues to be created")
+	}
+	if results[1].issue.SkipReason != skipReasonFilter {
+		t.Error("want redis to be skipped by filter")
+	}
+	if len(j.created) != 2 {
+		t.Errorf("want 2 created issues, got %d", len(j.created))
+	}
+}
+
+func TestHandlePostWebhook_missingFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := &fakeJira{}
+	s := HTTPServer{cfg: &config.Config{}, jira: j}
+	r := gin.New()
+	r.POST("/", s.handlePostWebhook)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty project", body: `{"provider":"npm","project":"","version":"1.3.0"}`},
+		{name: "missing version", body: `{"provider":"npm","project":"left-pad"}`},
+		{name: "batch", body: `[{"project":"left-pad","version":"1.3.0"},{"project":"left-pad"}]`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+	if len(j.created) != 0 {
+		t.Errorf("want 0 created issues, got %d", len(j.created))
+	}
+}
+
+func TestProcessReleases_skipPrereleases(t *testing.T) {
+	tests := []struct {
+		name        string
+		release     Release
+		wantSkipped bool
+	}{
+		{name: "prerelease", release: Release{Project: "neuvector", Version: "v5.2.0-rc.1", IsPrerelease: true}, wantSkipped: true},
+		{name: "stable", release: Release{Project: "neuvector", Version: "v5.1.3"}, wantSkipped: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{}
+			cfg := &config.Config{}
+			cfg.Filter.SkipPrereleases = true
+			s := HTTPServer{cfg: cfg, jira: j}
+
+			results := s.processReleases(context.Background(), []Release{tc.release})
+			if skipped := results[0].issue.SkipReason == skipReasonPrerelease; skipped != tc.wantSkipped {
+				t.Errorf("want skipped=%t, got %t", tc.wantSkipped, skipped)
+			}
+			if tc.wantSkipped && len(j.created) != 0 {
+				t.Errorf("want 0 created issues, got %d", len(j.created))
+			}
+			if !tc.wantSkipped && len(j.created) != 1 {
+				t.Errorf("want 1 created issue, got %d", len(j.created))
+			}
+		})
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Jira.URL = "https://jira.example.com/"
+	cfg.Jira.Connections = map[string]config.JiraConnection{
+		"other": {URL: "https://jira-other.example.com"},
+	}
+	cfg.Jira.ConnectionMapping = map[string]string{"npm": "other"}
+	s := HTTPServer{cfg: cfg}
+
+	if got, want := s.issueURL(Release{Provider: "github"}, "OP-1"), "https://jira.example.com/browse/OP-1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := s.issueURL(Release{Provider: "npm"}, "FE-1"), "https://jira-other.example.com/browse/FE-1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestMissingIssueLabels(t *testing.T) {
+	cfg := config.JiraIssue{
+		Labels:         []string{"jelease", "update"},
+		ProviderLabels: map[string][]string{"npm": {"frontend"}},
+	}
+	issue := jira.Issue{Labels: []string{"jelease", "added-by-human"}}
+
+	got, err := missingIssueLabels(issue, Release{Provider: "npm", Project: "react"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"update", "frontend", "react"}
+	if !slices.Equal(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestWriteJiraError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name           string
+		err            error
+		timedOut       bool
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "other error", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+		{name: "timed out", err: context.DeadlineExceeded, timedOut: true, wantStatus: http.StatusGatewayTimeout},
+		{
+			name:           "rate limited until timed out",
+			err:            &jira.RateLimitError{RetryAfter: 20 * time.Second, Err: fmt.Errorf("%w while waiting to retry: 429", context.DeadlineExceeded)},
+			timedOut:       true,
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "20",
+		},
+		{
+			name:           "rate limited",
+			err:            fmt.Errorf("creating Jira issue: %w", &jira.RateLimitError{RetryAfter: 1500 * time.Millisecond, Err: errors.New("429")}),
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "2",
+		},
+		{
+			name:       "rate limited without retry after",
+			err:        &jira.RateLimitError{Err: errors.New("429")},
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			ctx := context.Background()
+			if tc.timedOut {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, time.Now())
+				defer cancel()
+			}
+			writeJiraError(ctx, c, tc.err, gin.H{"error": tc.err.Error()})
+			if w.Code != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tc.wantRetryAfter {
+				t.Errorf("want Retry-After %q, got %q", tc.wantRetryAfter, got)
+			}
+		})
+	}
+}
+
+func TestEnsureJiraIssue_aliasedProject(t *testing.T) {
+	j := &fakeJira{}
+	cfg := &config.Config{AliasMapping: map[string]string{"foo": "@scope/foo"}}
+	s := HTTPServer{cfg: cfg}
+	release := s.canonicalRelease(Release{Project: "foo", Version: "v1.2.3"})
+
+	if _, err := ensureJiraIssue(context.Background(), j, release, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.created) != 1 {
+		t.Fatalf("want 1 created issue, got %d", len(j.created))
+	}
+	created := j.created[0]
+	if want := "Update @scope/foo to version v1.2.3"; created.Summary != want {
+		t.Errorf("want summary %q, got %q", want, created.Summary)
+	}
+	if created.PackageName != "@scope/foo" {
+		t.Errorf("want package name %q, got %q", "@scope/foo", created.PackageName)
+	}
+}
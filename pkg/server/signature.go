@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default headers set by newreleases.io on webhook requests, used when
+// the header names are not configured.
+// See: https://newreleases.io/webhooks
+const (
+	defaultSignatureHeader = "X-Newreleases-Signature"
+	defaultTimestampHeader = "X-Newreleases-Timestamp"
+)
+
+// headerOrDefault returns the configured header name, or the default if unset.
+func headerOrDefault(name, defaultName string) string {
+	if name == "" {
+		return defaultName
+	}
+	return name
+}
+
+// hasValidSignature checks the webhook signature of the request, if a
+// webhook secret is configured.
+func (s HTTPServer) hasValidSignature(c *gin.Context, body []byte) bool {
+	if s.cfg.HTTP.WebhookSecret == "" {
+		return true
+	}
+	timestamp := c.GetHeader(headerOrDefault(s.cfg.HTTP.TimestampHeader, defaultTimestampHeader))
+	signature := c.GetHeader(headerOrDefault(s.cfg.HTTP.SignatureHeader, defaultSignatureHeader))
+	return verifySignature(s.cfg.HTTP.WebhookSecret, timestamp, signature, body)
+}
+
+// maxSignatureAge is how far the timestamp of signed GET /issues requests may
+// be off from the current time, so captured requests cannot be replayed later.
+const maxSignatureAge = 5 * time.Minute
+
+// hasFreshTimestamp checks that the timestamp header of the request is
+// within [maxSignatureAge] of now, if a webhook secret is configured.
+func (s HTTPServer) hasFreshTimestamp(c *gin.Context, now time.Time) bool {
+	if s.cfg.HTTP.WebhookSecret == "" {
+		return true
+	}
+	timestamp := c.GetHeader(headerOrDefault(s.cfg.HTTP.TimestampHeader, defaultTimestampHeader))
+	return isFreshTimestamp(timestamp, now)
+}
+
+// isFreshTimestamp checks that the timestamp, in Unix seconds, is within
+// [maxSignatureAge] of now, in either direction to allow for clock skew.
+func isFreshTimestamp(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	return age <= maxSignatureAge && age >= -maxSignatureAge
+}
+
+// verifySignature checks the HMAC-SHA256 signature of a newreleases.io
+// webhook request. The signature is the hex-encoded HMAC of the timestamp
+// header value and the raw request body, joined by a dot.
+func verifySignature(secret, timestamp, signature string, body []byte) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const (
+		secret    = "my-secret"
+		timestamp = "1672502400"
+		body      = `{"provider":"github","project":"neuvector","version":"5.0.10"}`
+		// echo -n "$timestamp.$body" | openssl dgst -sha256 -hmac "$secret"
+		validSig = "a11f5294db5aca289deca37130d43cfa7a7700c23a07486cad7abbf79d7ce8c9"
+	)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		want      bool
+	}{
+		{
+			name:      "valid",
+			timestamp: timestamp,
+			signature: validSig,
+			body:      body,
+			want:      true,
+		},
+		{
+			name:      "tampered body",
+			timestamp: timestamp,
+			signature: validSig,
+			body:      body + " ",
+			want:      false,
+		},
+		{
+			name:      "wrong timestamp",
+			timestamp: "1672502401",
+			signature: validSig,
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "missing signature",
+			timestamp: timestamp,
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "non-hex signature",
+			timestamp: timestamp,
+			signature: "not-hex",
+			body:      body,
+			want:      false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := verifySignature(secret, tc.timestamp, tc.signature, []byte(tc.body))
+			if got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHandlePostWebhook_signatureHeaders(t *testing.T) {
+	const (
+		timestamp = "1672502400"
+		// Lacks the version, so valid signatures get past the signature
+		// check and are then rejected with 400 instead of 401.
+		body     = `{"provider":"github","project":"neuvector"}`
+		validSig = "adadd9fa96dcf3aa2cc0743b47f505146bbd504bdd095b5bbc172121cf2bb900"
+	)
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		signatureHeader string
+		timestampHeader string
+		sendHeaders     [2]string
+		want            int
+	}{
+		{
+			name:        "default headers",
+			sendHeaders: [2]string{"X-Newreleases-Signature", "X-Newreleases-Timestamp"},
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:            "custom headers",
+			signatureHeader: "X-Proxy-Signature",
+			timestampHeader: "X-Proxy-Timestamp",
+			sendHeaders:     [2]string{"X-Proxy-Signature", "X-Proxy-Timestamp"},
+			want:            http.StatusBadRequest,
+		},
+		{
+			name:            "custom headers not sent",
+			signatureHeader: "X-Proxy-Signature",
+			timestampHeader: "X-Proxy-Timestamp",
+			sendHeaders:     [2]string{"X-Newreleases-Signature", "X-Newreleases-Timestamp"},
+			want:            http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.HTTP.WebhookSecret = "my-secret"
+			cfg.HTTP.SignatureHeader = tc.signatureHeader
+			cfg.HTTP.TimestampHeader = tc.timestampHeader
+			s := HTTPServer{cfg: cfg}
+			r := gin.New()
+			r.POST("/", s.webhookHandlers()...)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(tc.sendHeaders[0], validSig)
+			req.Header.Set(tc.sendHeaders[1], timestamp)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("want status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
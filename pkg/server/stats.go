@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serverStats keeps track of when jelease last did something useful, so
+// external monitors can detect a silently broken setup, such as when the
+// webhook is misconfigured upstream. The stats are only kept in memory.
+type serverStats struct {
+	mu                  sync.Mutex
+	started             time.Time
+	lastWebhookReceived time.Time
+	lastIssueCreated    time.Time
+	lastError           time.Time
+	lastErrorMessage    string
+}
+
+// StatusResponse is the response of the /status endpoint, where timestamps
+// are null if the event has not happened since jelease was started.
+type StatusResponse struct {
+	Started             time.Time    `json:"started"`
+	Uptime              string       `json:"uptime"`
+	LastWebhookReceived *time.Time   `json:"lastWebhookReceived"`
+	LastIssueCreated    *time.Time   `json:"lastIssueCreated"`
+	LastError           *StatusError `json:"lastError"`
+}
+
+type StatusError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+func newServerStats() *serverStats {
+	return &serverStats{started: time.Now()}
+}
+
+// A nil *serverStats ignores all updates.
+func (s *serverStats) webhookReceived() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.lastWebhookReceived = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *serverStats) issueCreated() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.lastIssueCreated = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *serverStats) failed(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.lastError = time.Now()
+	s.lastErrorMessage = err.Error()
+	s.mu.Unlock()
+}
+
+func (s *serverStats) status(now time.Time) StatusResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := StatusResponse{
+		Started:             s.started,
+		Uptime:              now.Sub(s.started).Round(time.Second).String(),
+		LastWebhookReceived: timeOrNil(s.lastWebhookReceived),
+		LastIssueCreated:    timeOrNil(s.lastIssueCreated),
+	}
+	if !s.lastError.IsZero() {
+		resp.LastError = &StatusError{Time: s.lastError, Message: s.lastErrorMessage}
+	}
+	return resp
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// handleGetStatus handles GET requests for when jelease last received a
+// webhook, created an issue, and failed.
+func (s HTTPServer) handleGetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, s.stats.status(time.Now()))
+}
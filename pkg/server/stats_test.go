@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServerStats(t *testing.T) {
+	stats := newServerStats()
+	now := stats.started.Add(90 * time.Second)
+
+	got := stats.status(now)
+	if got.Uptime != "1m30s" {
+		t.Errorf("want uptime %q, got %q", "1m30s", got.Uptime)
+	}
+	if got.LastWebhookReceived != nil || got.LastIssueCreated != nil || got.LastError != nil {
+		t.Errorf("want no events before any webhook, got %+v", got)
+	}
+
+	stats.webhookReceived()
+	stats.issueCreated()
+	stats.failed(errors.New("jira unreachable"))
+	got = stats.status(now)
+	if got.LastWebhookReceived == nil || got.LastIssueCreated == nil {
+		t.Errorf("want webhook and issue times to be set, got %+v", got)
+	}
+	if got.LastError == nil || got.LastError.Message != "jira unreachable" {
+		t.Errorf("want last error %q, got %+v", "jira unreachable", got.LastError)
+	}
+}
+
+func TestServerStats_nil(t *testing.T) {
+	var stats *serverStats
+	stats.webhookReceived()
+	stats.issueCreated()
+	stats.failed(errors.New("ignored"))
+}
+
+func TestHandleGetStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := HTTPServer{stats: newServerStats()}
+	s.stats.webhookReceived()
+	r := gin.New()
+	r.GET("/status", s.handleGetStatus)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, w.Code)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["lastWebhookReceived"] == nil {
+		t.Errorf("want lastWebhookReceived to be set, got %v", got)
+	}
+	if v, ok := got["lastIssueCreated"]; !ok || v != nil {
+		t.Errorf("want lastIssueCreated to be null, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// bearerTokenMiddleware rejects requests that don't have the token in the
+// "Authorization: Bearer <token>" header.
+func bearerTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		got := strings.TrimPrefix(header, "Bearer ")
+		if got == header || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			log.Warn().
+				Str("remoteAddr", clientIP(c)).
+				Str("path", c.Request.URL.Path).
+				Msg("Rejected request with invalid bearer token.")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid bearer token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// handlePostTrigger handles manually triggered releases, which are processed
+// the same way as releases received via the webhook, but responds with the
+// resulting Jira issue.
+func (s HTTPServer) handlePostTrigger(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	release, err := decodeRelease(body, s.cfg.HTTP.StrictPayload)
+	if err == nil {
+		err = release.Validate()
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to decode trigger payload.")
+		metrics.IncWebhook(metrics.WebhookResultBadRequest)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	release = s.canonicalRelease(release)
+
+	ctx, cancel := s.jiraContext(c.Request.Context())
+	defer cancel()
+	issue, err := s.processRelease(ctx, release)
+	if err != nil {
+		writeJiraError(ctx, c, err, gin.H{"error": err.Error()})
+		return
+	}
+	s.startFollowUps([]releaseResult{{release: release, issue: issue}})
+	resp := gin.H{
+		"issue":  issue.Key,
+		"action": issue.Action(),
+	}
+	if issue.Key != "" {
+		resp["url"] = s.issueURL(release, issue.Key)
+	}
+	if issue.SkipReason != "" {
+		resp["skipReason"] = issue.SkipReason
+	}
+	if len(issue.Duplicates) > 0 {
+		resp["duplicates"] = issue.Duplicates
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBearerTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/", bearerTokenMiddleware("s3cr3t"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "valid", header: "Bearer s3cr3t", want: http.StatusOK},
+		{name: "wrong token", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "no bearer prefix", header: "s3cr3t", want: http.StatusUnauthorized},
+		{name: "missing", header: "", want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("want status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandlePostTrigger_skipped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := &fakeJira{}
+	cfg := &config.Config{}
+	cfg.Filter.Denylist = []string{"redis"}
+	s := HTTPServer{cfg: cfg, jira: j}
+	r := gin.New()
+	r.POST("/trigger", s.handlePostTrigger)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/trigger",
+		strings.NewReader(`{"provider":"github","project":"redis","version":"7.0.8"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, w.Code)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["action"] != "skipped" || got["skipReason"] != skipReasonFilter {
+		t.Errorf("want skipped by filter, got %v", got)
+	}
+	if len(j.created) != 0 {
+		t.Errorf("want 0 created issues, got %d", len(j.created))
+	}
+}
+
+func TestHandlePostTrigger_aliasedProjectFiltered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := &fakeJira{}
+	cfg := &config.Config{AliasMapping: map[string]string{"foo": "@scope/foo"}}
+	cfg.Filter.Denylist = []string{"@scope/foo"}
+	s := HTTPServer{cfg: cfg, jira: j}
+	r := gin.New()
+	r.POST("/trigger", s.handlePostTrigger)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/trigger",
+		strings.NewReader(`{"provider":"npm","project":"foo","version":"1.2.3"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(j.created) != 0 {
+		t.Errorf("want canonical name to be filtered, got %d created issues", len(j.created))
+	}
+}
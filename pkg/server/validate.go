@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// validatedIssue is the would-be Jira issue for a release, as responded
+// when validating a webhook payload.
+type validatedIssue struct {
+	Project     string   `json:"project"`
+	Type        string   `json:"type"`
+	Priority    string   `json:"priority,omitempty"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	FixVersion  string   `json:"fixVersion,omitempty"`
+}
+
+// respondValidatedIssues renders the Jira issues for the releases, without
+// searching, creating, or updating any issues, and responds with them.
+// Template errors are responded with 400 Bad Request.
+func (s HTTPServer) respondValidatedIssues(c *gin.Context, releases []Release, isBatch bool) {
+	issues := make([]validatedIssue, 0, len(releases))
+	for i, release := range releases {
+		issue, err := release.JiraIssue(&s.cfg.Jira.Issue)
+		if err != nil {
+			if isBatch {
+				err = fmt.Errorf("release at index %d: %w", i, err)
+			}
+			log.Warn().Err(err).Msg("Failed to validate webhook payload.")
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		issues = append(issues, validatedIssue{
+			Project:     issue.ProjectKey,
+			Type:        issue.TypeName,
+			Priority:    issue.Priority,
+			Summary:     issue.Summary,
+			Description: issue.Description,
+			Labels:      issue.Labels,
+			FixVersion:  issue.FixVersion,
+		})
+	}
+	if !isBatch {
+		c.JSON(http.StatusOK, issues[0])
+		return
+	}
+	c.JSON(http.StatusOK, issues)
+}
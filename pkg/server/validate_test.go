@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2022 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlePostWebhook_validate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	j := &fakeJira{}
+	cfg := &config.Config{}
+	cfg.HTTP.Validate.Enabled = true
+	cfg.Jira.Issue.Project = "OP"
+	cfg.Jira.Issue.Type = "Task"
+	cfg.Jira.Issue.Labels = []string{"jelease"}
+	s := HTTPServer{cfg: cfg, jira: j}
+	r := gin.New()
+	r.POST("/", s.handlePostWebhook)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/?validate=true",
+		strings.NewReader(`{"provider":"npm","project":"left-pad","version":"1.3.0"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	var got validatedIssue
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary != "Update left-pad to version 1.3.0" || got.Project != "OP" || got.Type != "Task" {
+		t.Errorf("unexpected issue: %+v", got)
+	}
+	if len(j.created) != 0 || len(j.updated) != 0 {
+		t.Errorf("want Jira to be untouched, got %d created and %d updated", len(j.created), len(j.updated))
+	}
+}
+
+func TestHandlePostWebhook_validateTemplateError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var summary config.Template
+	if err := summary.Set("Update {{ .NoSuchField }}"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.HTTP.Validate.Enabled = true
+	cfg.Jira.Issue.Summary = &summary
+	s := HTTPServer{cfg: cfg, jira: &fakeJira{}}
+	r := gin.New()
+	r.POST("/", s.handlePostWebhook)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/?validate=true",
+		strings.NewReader(`{"provider":"npm","project":"left-pad","version":"1.3.0"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("want status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "NoSuchField") {
+		t.Errorf("want template error in response, got %s", w.Body)
+	}
+}
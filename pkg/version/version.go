@@ -96,6 +96,38 @@ func (v Version) Bump(other Version) Version {
 	}
 }
 
+// Segment returns the version segment at the given index, or zero if the
+// version has fewer segments. E.g index 0 is the major version.
+func (v Version) Segment(index int) uint {
+	return indexOrZero(v.Segments, index)
+}
+
+// Compare returns -1, 0, or +1 depending on whether v is less than, equal
+// to, or greater than the other version. Missing segments are treated as zero
+// and the prefix is ignored. A version with a suffix, such as "1.2.3-rc.1",
+// is considered less than the same version without a suffix.
+func (v Version) Compare(other Version) int {
+	max := typ.Max(len(v.Segments), len(other.Segments))
+	for i := 0; i < max; i++ {
+		a, b := indexOrZero(v.Segments, i), indexOrZero(other.Segments, i)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	switch {
+	case v.Suffix == other.Suffix:
+		return 0
+	case v.Suffix == "":
+		return 1
+	case other.Suffix == "":
+		return -1
+	}
+	return strings.Compare(v.Suffix, other.Suffix)
+}
+
 func indexOrZero(slice []uint, index int) uint {
 	if index < 0 || index >= len(slice) {
 		return 0
@@ -194,3 +194,36 @@ func TestBump(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{name: "equal", a: "1.2.3", b: "1.2.3", want: 0},
+		{name: "ignores prefix", a: "v1.2.3", b: "1.2.3", want: 0},
+		{name: "missing segments", a: "1.2", b: "1.2.0", want: 0},
+		{name: "less patch", a: "1.2.3", b: "1.2.4", want: -1},
+		{name: "greater minor", a: "1.10.0", b: "1.9.9", want: 1},
+		{name: "prerelease is less", a: "1.2.3-rc.1", b: "1.2.3", want: -1},
+		{name: "release is greater", a: "1.2.3", b: "1.2.3-rc.1", want: 1},
+		{name: "prerelease order", a: "1.2.3-rc.1", b: "1.2.3-rc.2", want: -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := Parse(tc.a)
+			if err != nil {
+				t.Fatalf("parse a: %s", err)
+			}
+			b, err := Parse(tc.b)
+			if err != nil {
+				t.Fatalf("parse b: %s", err)
+			}
+			if got := a.Compare(b); got != tc.want {
+				t.Errorf("want %d, got %d", tc.want, got)
+			}
+		})
+	}
+}